@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+	"github.com/edtech-mastery/student-progress-service/internal/mastery"
+	"github.com/edtech-mastery/student-progress-service/internal/queue"
+	"github.com/edtech-mastery/student-progress-service/internal/risk"
+	"github.com/edtech-mastery/student-progress-service/internal/rollups"
+)
+
+func listDeadLettersHandler(log zerolog.Logger, dl *queue.DeadLetters) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		afterID, _ := strconv.ParseInt(r.URL.Query().Get("after_id"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		items, err := dl.List(r.Context(), afterID, limit)
+		if err != nil {
+			log.Warn().Err(err).Msg("list dead letters")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"dead_letters": items})
+	}
+}
+
+func getDeadLetterHandler(log zerolog.Logger, dl *queue.DeadLetters) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		outboxID, ok := outboxIDParam(w, r)
+		if !ok {
+			return
+		}
+		item, err := dl.Get(r.Context(), outboxID)
+		if err != nil {
+			log.Warn().Err(err).Int64("outbox_id", outboxID).Msg("get dead letter")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		if item == nil {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(item)
+	}
+}
+
+func requeueDeadLetterHandler(log zerolog.Logger, dl *queue.DeadLetters) http.HandlerFunc {
+	return deadLetterActionHandler(log, "requeue", dl.Requeue)
+}
+
+func discardDeadLetterHandler(log zerolog.Logger, dl *queue.DeadLetters) http.HandlerFunc {
+	return deadLetterActionHandler(log, "discard", dl.Discard)
+}
+
+func deadLetterActionHandler(log zerolog.Logger, action string, fn func(ctx context.Context, outboxID int64) (bool, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		outboxID, ok := outboxIDParam(w, r)
+		if !ok {
+			return
+		}
+		found, err := fn(r.Context(), outboxID)
+		if err != nil {
+			log.Warn().Err(err).Int64("outbox_id", outboxID).Str("action", action).Msg("dead letter action")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		log.Info().Int64("outbox_id", outboxID).Str("action", action).Msg("dead letter action")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func outboxIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "outboxID"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error":"invalid outbox id"}`, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func rebuildRollupsHandler(log zerolog.Logger, svc *rollups.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		classID := chi.URLParam(r, "classID")
+		if err := svc.RebuildForClass(r.Context(), classID); err != nil {
+			log.Warn().Err(err).Str("class_id", classID).Msg("rebuild rollups")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		log.Info().Str("class_id", classID).Msg("rollups rebuilt")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func listMasteryModelsHandler(log zerolog.Logger, svc *mastery.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		settings, err := svc.ListSettings(r.Context())
+		if err != nil {
+			log.Warn().Err(err).Msg("list mastery model settings")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"models":   mastery.ModelNames(),
+			"default":  mastery.DefaultModel,
+			"settings": settings,
+		})
+	}
+}
+
+func putMasteryModelHandler(log zerolog.Logger, svc *mastery.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model  string          `json:"model"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+			return
+		}
+		setting := domain.MasteryModelSetting{
+			Scope:   chi.URLParam(r, "scope"),
+			ScopeID: chi.URLParam(r, "scopeID"),
+			Model:   body.Model,
+			Params:  body.Params,
+		}
+		if err := svc.SaveSetting(r.Context(), setting); err != nil {
+			log.Warn().Err(err).Str("scope", setting.Scope).Str("scope_id", setting.ScopeID).Msg("save mastery model setting")
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		log.Info().Str("scope", setting.Scope).Str("scope_id", setting.ScopeID).Str("model", setting.Model).Msg("mastery model set")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func deleteMasteryModelHandler(log zerolog.Logger, svc *mastery.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope, scopeID := chi.URLParam(r, "scope"), chi.URLParam(r, "scopeID")
+		found, err := svc.DeleteSetting(r.Context(), scope, scopeID)
+		if err != nil {
+			log.Warn().Err(err).Str("scope", scope).Str("scope_id", scopeID).Msg("delete mastery model setting")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func listMasteryBandsHandler(log zerolog.Logger, svc *mastery.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		settings, err := svc.ListBandSettings(r.Context())
+		if err != nil {
+			log.Warn().Err(err).Msg("list mastery band settings")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"default":  mastery.DefaultCuts,
+			"settings": settings,
+		})
+	}
+}
+
+func putMasteryBandsHandler(log zerolog.Logger, svc *mastery.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var setting domain.MasteryBandSetting
+		if err := json.NewDecoder(r.Body).Decode(&setting); err != nil {
+			http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+			return
+		}
+		setting.Scope, setting.ScopeID = chi.URLParam(r, "scope"), chi.URLParam(r, "scopeID")
+		if err := svc.SaveBandSetting(r.Context(), setting); err != nil {
+			log.Warn().Err(err).Str("scope", setting.Scope).Str("scope_id", setting.ScopeID).Msg("save mastery band setting")
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		log.Info().Str("scope", setting.Scope).Str("scope_id", setting.ScopeID).Msg("mastery bands set")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func deleteMasteryBandsHandler(log zerolog.Logger, svc *mastery.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope, scopeID := chi.URLParam(r, "scope"), chi.URLParam(r, "scopeID")
+		found, err := svc.DeleteBandSetting(r.Context(), scope, scopeID)
+		if err != nil {
+			log.Warn().Err(err).Str("scope", scope).Str("scope_id", scopeID).Msg("delete mastery band setting")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func listRiskSettingsHandler(log zerolog.Logger, svc *risk.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		settings, err := svc.ListSettings(r.Context())
+		if err != nil {
+			log.Warn().Err(err).Msg("list risk settings")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"default":  risk.DefaultConfig,
+			"rules":    svc.Rules(),
+			"settings": settings,
+		})
+	}
+}
+
+func putRiskSettingHandler(log zerolog.Logger, svc *risk.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Omitted thresholds keep their defaults; omitted enabled_rules runs every rule.
+		cfg := risk.DefaultConfig
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+			return
+		}
+		scope, scopeID := chi.URLParam(r, "scope"), chi.URLParam(r, "scopeID")
+		if err := svc.SaveSetting(r.Context(), scope, scopeID, cfg); err != nil {
+			log.Warn().Err(err).Str("scope", scope).Str("scope_id", scopeID).Msg("save risk setting")
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		log.Info().Str("scope", scope).Str("scope_id", scopeID).Msg("risk thresholds set")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func deleteRiskSettingHandler(log zerolog.Logger, svc *risk.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope, scopeID := chi.URLParam(r, "scope"), chi.URLParam(r, "scopeID")
+		found, err := svc.DeleteSetting(r.Context(), scope, scopeID)
+		if err != nil {
+			log.Warn().Err(err).Str("scope", scope).Str("scope_id", scopeID).Msg("delete risk setting")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func putClassDistrictHandler(log zerolog.Logger, svc *mastery.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			DistrictID string `json:"district_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+			return
+		}
+		classID := chi.URLParam(r, "classID")
+		if err := svc.SetClassDistrict(r.Context(), classID, body.DistrictID); err != nil {
+			log.Warn().Err(err).Str("class_id", classID).Msg("set class district")
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func getAssignmentWeightsHandler(log zerolog.Logger, svc *mastery.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assignmentID := chi.URLParam(r, "assignmentID")
+		weights, err := svc.AssignmentWeights(r.Context(), assignmentID)
+		if err != nil {
+			log.Warn().Err(err).Str("assignment_id", assignmentID).Msg("get assignment weights")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		if weights == nil {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(weights)
+	}
+}
+
+func putAssignmentWeightsHandler(log zerolog.Logger, svc *mastery.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body domain.AssignmentWeights
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+			return
+		}
+		body.AssignmentID = chi.URLParam(r, "assignmentID")
+		if err := svc.SaveAssignmentWeights(r.Context(), body); err != nil {
+			log.Warn().Err(err).Str("assignment_id", body.AssignmentID).Msg("save assignment weights")
+			status := http.StatusInternalServerError
+			if errors.Is(err, mastery.ErrInvalidWeights) {
+				status = http.StatusBadRequest
+			}
+			http.Error(w, `{"error":"`+err.Error()+`"}`, status)
+			return
+		}
+		log.Info().Str("assignment_id", body.AssignmentID).Int("standards", len(body.Weights)).Msg("assignment weights set")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func deleteAssignmentWeightsHandler(log zerolog.Logger, svc *mastery.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assignmentID := chi.URLParam(r, "assignmentID")
+		found, err := svc.DeleteAssignmentWeights(r.Context(), assignmentID)
+		if err != nil {
+			log.Warn().Err(err).Str("assignment_id", assignmentID).Msg("delete assignment weights")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
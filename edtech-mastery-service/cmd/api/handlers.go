@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -11,6 +15,7 @@ import (
 	"github.com/edtech-mastery/student-progress-service/internal/domain"
 	"github.com/edtech-mastery/student-progress-service/internal/dashboard"
 	"github.com/edtech-mastery/student-progress-service/internal/events"
+	"github.com/edtech-mastery/student-progress-service/internal/risk"
 	"github.com/edtech-mastery/student-progress-service/pkg/logging"
 	"github.com/edtech-mastery/student-progress-service/pkg/metrics"
 )
@@ -48,6 +53,38 @@ func eventsHandler(log zerolog.Logger, svc *events.Service) http.HandlerFunc {
 	}
 }
 
+// maxBatchBodyBytes caps the request body of POST /events:batch.
+const maxBatchBodyBytes = 32 << 20
+
+func batchEventsHandler(log zerolog.Logger, svc *events.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		reqID := r.Header.Get("X-Request-ID")
+		l := logging.WithRequestID(log, reqID)
+
+		ndjson := strings.Contains(r.Header.Get("Content-Type"), "ndjson")
+		raws, err := events.DecodeBatch(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes), ndjson)
+		if err != nil {
+			l.Warn().Err(err).Msg("decode batch")
+			status := http.StatusBadRequest
+			var tooBig *http.MaxBytesError
+			if errors.Is(err, events.ErrBatchTooLarge) || errors.As(err, &tooBig) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, `{"error":"`+err.Error()+`"}`, status)
+			return
+		}
+		resp := svc.IngestBatch(r.Context(), raws)
+		metrics.IngestionLatency.Observe(time.Since(start).Seconds())
+		l.Info().Int("items", len(raws)).Int("accepted", resp.Accepted).Int("duplicates", resp.Duplicates).
+			Int("invalid", resp.Invalid).Int("failed", resp.Failed).Msg("ingest batch")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
 func dashboardHandler(log zerolog.Logger, svc *dashboard.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -70,7 +107,12 @@ func masteryHandler(log zerolog.Logger, svc *dashboard.Service) http.HandlerFunc
 		start := time.Now()
 		defer func() { metrics.DashboardQueryLatency.WithLabelValues("student_mastery").Observe(time.Since(start).Seconds()) }()
 		studentID := chi.URLParam(r, "studentID")
-		m, err := svc.StudentMastery(r.Context(), studentID)
+		view := r.URL.Query().Get("view")
+		if view != "" && view != "flat" && view != "tree" {
+			http.Error(w, `{"error":"view must be flat or tree"}`, http.StatusBadRequest)
+			return
+		}
+		m, err := svc.StudentMastery(r.Context(), studentID, view == "tree")
 		if err != nil {
 			log.Warn().Err(err).Msg("mastery")
 			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
@@ -81,6 +123,87 @@ func masteryHandler(log zerolog.Logger, svc *dashboard.Service) http.HandlerFunc
 	}
 }
 
+func standardEvidenceHandler(log zerolog.Logger, svc *dashboard.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		defer func() { metrics.DashboardQueryLatency.WithLabelValues("standard_evidence").Observe(time.Since(start).Seconds()) }()
+		studentID := chi.URLParam(r, "studentID")
+		standardID := chi.URLParam(r, "standardID")
+		afterID, _ := strconv.ParseInt(r.URL.Query().Get("after_id"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		v, err := svc.StandardEvidence(r.Context(), studentID, standardID, afterID, limit)
+		if err != nil {
+			log.Warn().Err(err).Msg("standard evidence")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		if v == nil {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
+
+// bandTransitionsWindow is how far back GET /classes/{classID}/band-transitions looks without ?since.
+const bandTransitionsWindow = 7 * 24 * time.Hour
+
+func bandTransitionsHandler(log zerolog.Logger, svc *dashboard.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		defer func() { metrics.DashboardQueryLatency.WithLabelValues("band_transitions").Observe(time.Since(start).Seconds()) }()
+		classID := chi.URLParam(r, "classID")
+		q := r.URL.Query()
+		since := start.Add(-bandTransitionsWindow)
+		if s := q.Get("since"); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				http.Error(w, `{"error":"since must be RFC3339"}`, http.StatusBadRequest)
+				return
+			}
+			since = t
+		}
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		transitions, err := svc.BandTransitions(r.Context(), classID, since, q.Get("dropped_below"), q.Get("reached"), limit)
+		if errors.Is(err, dashboard.ErrUnknownBand) {
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Warn().Err(err).Msg("band transitions")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"class_id": classID, "since": since, "transitions": transitions})
+	}
+}
+
+func assignmentStatusHandler(log zerolog.Logger, svc *dashboard.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		defer func() {
+			metrics.DashboardQueryLatency.WithLabelValues("assignment_status").Observe(time.Since(start).Seconds())
+		}()
+		classID := chi.URLParam(r, "classID")
+		q := r.URL.Query()
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		statuses, err := svc.AssignmentStatuses(r.Context(), classID, q.Get("student_id"), q.Get("status"), limit)
+		if errors.Is(err, dashboard.ErrUnknownStatus) {
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Warn().Err(err).Msg("assignment status")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"class_id": classID, "assignments": statuses})
+	}
+}
+
 func timelineHandler(log zerolog.Logger, svc *dashboard.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -97,3 +220,62 @@ func timelineHandler(log zerolog.Logger, svc *dashboard.Service) http.HandlerFun
 		_ = json.NewEncoder(w).Encode(t)
 	}
 }
+
+func riskHistoryHandler(log zerolog.Logger, svc *risk.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		defer func() {
+			metrics.DashboardQueryLatency.WithLabelValues("risk_history").Observe(time.Since(start).Seconds())
+		}()
+		classID := chi.URLParam(r, "classID")
+		studentID := chi.URLParam(r, "studentID")
+		h, err := svc.History(r.Context(), classID, studentID)
+		if err != nil {
+			log.Warn().Err(err).Str("class_id", classID).Str("student_id", studentID).Msg("risk history")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(h)
+	}
+}
+
+// riskFlagActionFunc is risk.Service's Acknowledge or Resolve.
+type riskFlagActionFunc func(ctx context.Context, classID, studentID, reason, teacherID, note string) (*domain.RiskFlag, error)
+
+// riskFlagActionHandler acknowledges or resolves a flag; both take {"teacher_id": ..., "note": ...}.
+func riskFlagActionHandler(log zerolog.Logger, action riskFlagActionFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			TeacherID string `json:"teacher_id"`
+			Note      string `json:"note"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+			return
+		}
+		if body.TeacherID == "" {
+			http.Error(w, `{"error":"teacher_id is required"}`, http.StatusBadRequest)
+			return
+		}
+		classID := chi.URLParam(r, "classID")
+		studentID := chi.URLParam(r, "studentID")
+		reason := chi.URLParam(r, "reason")
+		f, err := action(r.Context(), classID, studentID, reason, body.TeacherID, body.Note)
+		if errors.Is(err, risk.ErrFlagClosed) {
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("class_id", classID).Str("student_id", studentID).Str("reason", reason).Msg("risk flag action")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		if f == nil {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(f)
+	}
+}
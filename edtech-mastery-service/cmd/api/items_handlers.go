@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/edtech-mastery/student-progress-service/internal/items"
+)
+
+func itemAnalysisHandler(log zerolog.Logger, svc *items.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assignmentID := chi.URLParam(r, "assignmentID")
+		classID := r.URL.Query().Get("class_id")
+		analysis, err := svc.Analysis(r.Context(), assignmentID, classID)
+		if err != nil {
+			log.Warn().Err(err).Str("assignment_id", assignmentID).Msg("item analysis")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(analysis)
+	}
+}
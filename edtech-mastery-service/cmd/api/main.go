@@ -15,6 +15,12 @@ import (
 
 	"github.com/edtech-mastery/student-progress-service/internal/dashboard"
 	"github.com/edtech-mastery/student-progress-service/internal/events"
+	"github.com/edtech-mastery/student-progress-service/internal/items"
+	"github.com/edtech-mastery/student-progress-service/internal/mastery"
+	"github.com/edtech-mastery/student-progress-service/internal/queue"
+	"github.com/edtech-mastery/student-progress-service/internal/risk"
+	"github.com/edtech-mastery/student-progress-service/internal/rollups"
+	"github.com/edtech-mastery/student-progress-service/internal/standards"
 	"github.com/edtech-mastery/student-progress-service/internal/storage"
 	"github.com/edtech-mastery/student-progress-service/pkg/logging"
 )
@@ -41,7 +47,14 @@ func main() {
 	recentRepo := storage.NewRecentActivityRepo(pool)
 	masteryRepo := storage.NewMasteryRepo(pool)
 	timelineRepo := storage.NewTimelineRepo(pool)
-	dashboardSvc := dashboard.NewService(rollupsRepo, riskRepo, recentRepo, masteryRepo, timelineRepo)
+	standardsRepo := storage.NewStandardsRepo(pool)
+	dashboardSvc := dashboard.NewService(rollupsRepo, riskRepo, recentRepo, masteryRepo, timelineRepo, standardsRepo)
+	deadLetters := queue.NewDeadLetters(storage.NewOutboxRepo(pool))
+	rollupsSvc := rollups.NewService(pool, rollupsRepo)
+	masterySvc := mastery.NewService(masteryRepo, standardsRepo)
+	standardsSvc := standards.NewService(storage.NewUnitOfWork(pool), standardsRepo)
+	itemsSvc := items.NewService(storage.NewItemsRepo(pool))
+	riskSvc := risk.NewService(riskRepo, risk.DefaultRegistry())
 
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
@@ -50,9 +63,44 @@ func main() {
 	r.Use(middleware.Recoverer)
 
 	r.Post("/events", eventsHandler(log, eventsSvc))
+	r.Post("/events:batch", batchEventsHandler(log, eventsSvc))
 	r.Get("/teachers/{teacherID}/classes/{classID}/dashboard", dashboardHandler(log, dashboardSvc))
 	r.Get("/students/{studentID}/mastery", masteryHandler(log, dashboardSvc))
+	r.Get("/students/{studentID}/standards/{standardID}/evidence", standardEvidenceHandler(log, dashboardSvc))
 	r.Get("/classes/{classID}/students/{studentID}/timeline", timelineHandler(log, dashboardSvc))
+	r.Get("/classes/{classID}/band-transitions", bandTransitionsHandler(log, dashboardSvc))
+	r.Get("/classes/{classID}/assignment-status", assignmentStatusHandler(log, dashboardSvc))
+	r.Get("/classes/{classID}/students/{studentID}/risk-history", riskHistoryHandler(log, riskSvc))
+	r.Post("/classes/{classID}/students/{studentID}/risk-flags/{reason}/acknowledge", riskFlagActionHandler(log, riskSvc.Acknowledge))
+	r.Post("/classes/{classID}/students/{studentID}/risk-flags/{reason}/resolve", riskFlagActionHandler(log, riskSvc.Resolve))
+	r.Get("/assignments/{assignmentID}/item-analysis", itemAnalysisHandler(log, itemsSvc))
+	r.Get("/frameworks", listFrameworksHandler(log, standardsSvc))
+	r.Get("/frameworks/{frameworkID}", frameworkTreeHandler(log, standardsSvc))
+	r.Get("/standards/{standardID}", getStandardHandler(log, standardsSvc))
+	r.Route("/admin", func(r chi.Router) {
+		r.Get("/dead-letters", listDeadLettersHandler(log, deadLetters))
+		r.Get("/dead-letters/{outboxID}", getDeadLetterHandler(log, deadLetters))
+		r.Post("/dead-letters/{outboxID}/requeue", requeueDeadLetterHandler(log, deadLetters))
+		r.Delete("/dead-letters/{outboxID}", discardDeadLetterHandler(log, deadLetters))
+		r.Post("/classes/{classID}/rollups/rebuild", rebuildRollupsHandler(log, rollupsSvc))
+		r.Get("/mastery-models", listMasteryModelsHandler(log, masterySvc))
+		r.Put("/mastery-models/{scope}/{scopeID}", putMasteryModelHandler(log, masterySvc))
+		r.Delete("/mastery-models/{scope}/{scopeID}", deleteMasteryModelHandler(log, masterySvc))
+		r.Get("/mastery-bands", listMasteryBandsHandler(log, masterySvc))
+		r.Put("/mastery-bands/{scope}/{scopeID}", putMasteryBandsHandler(log, masterySvc))
+		r.Delete("/mastery-bands/{scope}/{scopeID}", deleteMasteryBandsHandler(log, masterySvc))
+		r.Put("/classes/{classID}/district", putClassDistrictHandler(log, masterySvc))
+		r.Get("/risk-settings", listRiskSettingsHandler(log, riskSvc))
+		r.Put("/risk-settings/{scope}/{scopeID}", putRiskSettingHandler(log, riskSvc))
+		r.Delete("/risk-settings/{scope}/{scopeID}", deleteRiskSettingHandler(log, riskSvc))
+		r.Get("/assignments/{assignmentID}/standard-weights", getAssignmentWeightsHandler(log, masterySvc))
+		r.Put("/assignments/{assignmentID}/standard-weights", putAssignmentWeightsHandler(log, masterySvc))
+		r.Delete("/assignments/{assignmentID}/standard-weights", deleteAssignmentWeightsHandler(log, masterySvc))
+		r.Put("/frameworks/{frameworkID}", putFrameworkHandler(log, standardsSvc))
+		r.Post("/frameworks:import", importCASEHandler(log, standardsSvc))
+		r.Put("/standards/{standardID}", putStandardHandler(log, standardsSvc))
+		r.Delete("/standards/{standardID}", deleteStandardHandler(log, standardsSvc))
+	})
 	r.Handle("/metrics", promhttp.Handler())
 
 	port := os.Getenv("PORT")
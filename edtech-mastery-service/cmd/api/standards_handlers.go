@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+	"github.com/edtech-mastery/student-progress-service/internal/standards"
+)
+
+func listFrameworksHandler(log zerolog.Logger, svc *standards.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		frameworks, err := svc.Frameworks(r.Context())
+		if err != nil {
+			log.Warn().Err(err).Msg("list frameworks")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"frameworks": frameworks})
+	}
+}
+
+func frameworkTreeHandler(log zerolog.Logger, svc *standards.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		frameworkID := chi.URLParam(r, "frameworkID")
+		tree, err := svc.FrameworkTree(r.Context(), frameworkID)
+		if err != nil {
+			log.Warn().Err(err).Str("framework_id", frameworkID).Msg("framework tree")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		if tree == nil {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(tree)
+	}
+}
+
+func getStandardHandler(log zerolog.Logger, svc *standards.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		standardID := chi.URLParam(r, "standardID")
+		item, err := svc.Standard(r.Context(), standardID)
+		if err != nil {
+			log.Warn().Err(err).Str("standard_id", standardID).Msg("get standard")
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		if item == nil {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(item)
+	}
+}
+
+func putFrameworkHandler(log zerolog.Logger, svc *standards.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var f domain.Framework
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+			http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+			return
+		}
+		f.ID = chi.URLParam(r, "frameworkID")
+		if err := svc.SaveFramework(r.Context(), f); err != nil {
+			writeCatalogError(w, log, err, "save framework")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func putStandardHandler(log zerolog.Logger, svc *standards.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var item domain.StandardItem
+		if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+			http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+			return
+		}
+		item.ID = chi.URLParam(r, "standardID")
+		if err := svc.SaveStandard(r.Context(), item); err != nil {
+			writeCatalogError(w, log, err, "save standard")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func deleteStandardHandler(log zerolog.Logger, svc *standards.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		found, err := svc.DeleteStandard(r.Context(), chi.URLParam(r, "standardID"))
+		if err != nil {
+			writeCatalogError(w, log, err, "delete standard")
+			return
+		}
+		if !found {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// maxCASEPackageBytes caps the request body of POST /admin/frameworks:import.
+const maxCASEPackageBytes = 64 << 20
+
+func importCASEHandler(log zerolog.Logger, svc *standards.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dryRun := r.URL.Query().Get("dry_run") == "true"
+		report, err := svc.ImportCASE(r.Context(), http.MaxBytesReader(w, r.Body, maxCASEPackageBytes), dryRun)
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			http.Error(w, `{"error":"package too large"}`, http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			writeCatalogError(w, log, err, "import CASE package")
+			return
+		}
+		log.Info().Str("framework_id", report.FrameworkID).Bool("dry_run", dryRun).Int("added", len(report.Added)).
+			Int("updated", len(report.Updated)).Int("missing", len(report.Missing)).Msg("CASE package imported")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(report)
+	}
+}
+
+func writeCatalogError(w http.ResponseWriter, log zerolog.Logger, err error, msg string) {
+	switch {
+	case errors.Is(err, standards.ErrInvalidStandard), errors.Is(err, standards.ErrInvalidPackage):
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+	case errors.Is(err, standards.ErrHasChildren), errors.Is(err, standards.ErrOtherFramework):
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusConflict)
+	default:
+		log.Warn().Err(err).Msg(msg)
+		http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+	}
+}
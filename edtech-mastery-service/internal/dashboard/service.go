@@ -2,26 +2,37 @@ package dashboard
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/edtech-mastery/student-progress-service/internal/domain"
+	"github.com/edtech-mastery/student-progress-service/internal/standards"
 	"github.com/edtech-mastery/student-progress-service/internal/storage"
 )
 
+var (
+	ErrUnknownBand   = errors.New("unknown band")
+	ErrUnknownStatus = errors.New("unknown assignment status")
+)
+
 type Service struct {
 	rollups   *storage.RollupsRepo
 	risk      *storage.RiskRepo
 	recent    *storage.RecentActivityRepo
 	mastery   *storage.MasteryRepo
 	timeline  *storage.TimelineRepo
+	standards *storage.StandardsRepo
 }
 
-func NewService(rollups *storage.RollupsRepo, risk *storage.RiskRepo, recent *storage.RecentActivityRepo, mastery *storage.MasteryRepo, timeline *storage.TimelineRepo) *Service {
+func NewService(rollups *storage.RollupsRepo, risk *storage.RiskRepo, recent *storage.RecentActivityRepo, mastery *storage.MasteryRepo, timeline *storage.TimelineRepo, standards *storage.StandardsRepo) *Service {
 	return &Service{
-		rollups:  rollups,
-		risk:     risk,
-		recent:   recent,
-		mastery:  mastery,
-		timeline: timeline,
+		rollups:   rollups,
+		risk:      risk,
+		recent:    recent,
+		mastery:   mastery,
+		timeline:  timeline,
+		standards: standards,
 	}
 }
 
@@ -30,6 +41,10 @@ func (s *Service) TeacherDashboard(ctx context.Context, teacherID, classID strin
 	if err != nil {
 		return nil, err
 	}
+	late, missing, err := s.rollups.StatusCounts(ctx, classID)
+	if err != nil {
+		return nil, err
+	}
 	atRisk, err := s.risk.GetAtRiskByClass(ctx, classID)
 	if err != nil {
 		return nil, err
@@ -42,17 +57,76 @@ func (s *Service) TeacherDashboard(ctx context.Context, teacherID, classID strin
 		ClassID:        classID,
 		CompletionRate: rollup.CompletionRate,
 		AverageScore:   rollup.AvgScore,
+		LateCount:      late,
+		MissingCount:   missing,
 		AtRiskStudents: atRisk,
 		RecentActivity: recent,
 	}, nil
 }
 
-func (s *Service) StudentMastery(ctx context.Context, studentID string) (*domain.StudentMasteryView, error) {
+// StudentMastery returns the student's mastery per standard; with tree set it is also rolled up the standards catalog.
+func (s *Service) StudentMastery(ctx context.Context, studentID string, tree bool) (*domain.StudentMasteryView, error) {
 	mastery, err := s.mastery.GetMasteryByStudent(ctx, studentID)
 	if err != nil {
 		return nil, err
 	}
-	return &domain.StudentMasteryView{StudentID: studentID, Mastery: mastery}, nil
+	view := &domain.StudentMasteryView{StudentID: studentID, Mastery: mastery}
+	if !tree || len(mastery) == 0 {
+		return view, nil
+	}
+	ids := make([]string, len(mastery))
+	for i, m := range mastery {
+		ids[i] = m.StandardID
+	}
+	items, err := s.standards.StandardsWithAncestors(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	frameworks, err := s.standards.ListFrameworks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	view.Tree, view.Uncataloged = standards.BuildMasteryTree(frameworks, items, mastery)
+	return view, nil
+}
+
+// StandardEvidence returns nil if the student has no mastery of the standard yet.
+func (s *Service) StandardEvidence(ctx context.Context, studentID, standardID string, afterID int64, limit int) (*domain.StandardEvidenceView, error) {
+	current, err := s.mastery.GetMastery(ctx, studentID, standardID)
+	if err != nil || current == nil {
+		return nil, err
+	}
+	evidence, err := s.mastery.ListEvidence(ctx, studentID, standardID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.StandardEvidenceView{StudentID: studentID, StandardID: standardID, Current: current, Evidence: evidence}, nil
+}
+
+// BandTransitions lists the class's band changes since the given time. droppedBelow and reached are
+// optional band names: keep only students who fell below, or rose to, that band.
+func (s *Service) BandTransitions(ctx context.Context, classID string, since time.Time, droppedBelow, reached string, limit int) ([]domain.BandTransition, error) {
+	q := storage.BandTransitionQuery{ClassID: classID, Since: since, DroppedBelowRank: -1, ReachedRank: -1, Limit: limit}
+	if droppedBelow != "" {
+		if q.DroppedBelowRank = domain.BandRank(droppedBelow); q.DroppedBelowRank < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownBand, droppedBelow)
+		}
+	}
+	if reached != "" {
+		if q.ReachedRank = domain.BandRank(reached); q.ReachedRank < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownBand, reached)
+		}
+	}
+	return s.mastery.ListBandTransitions(ctx, q)
+}
+
+// AssignmentStatuses lists where the class's students stand on their assignments, optionally for one student
+// or one status.
+func (s *Service) AssignmentStatuses(ctx context.Context, classID, studentID, status string, limit int) ([]domain.AssignmentStatus, error) {
+	if status != "" && !domain.ValidAssignmentStatus(status) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStatus, status)
+	}
+	return s.rollups.ListAssignmentStatuses(ctx, storage.AssignmentStatusQuery{ClassID: classID, StudentID: studentID, Status: status, Limit: limit})
 }
 
 func (s *Service) StudentTimeline(ctx context.Context, studentID, classID string, limit int) (*domain.StudentTimeline, error) {
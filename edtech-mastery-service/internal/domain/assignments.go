@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Assignment statuses of a student's (student, assignment) pair. A submission is on time unless it came after the
+// due date; an assignment without a due date is never missing.
+const (
+	AssignmentNotYetDue = "not_yet_due"
+	AssignmentOnTime    = "on_time"
+	AssignmentLate      = "late"
+	AssignmentMissing   = "missing"
+)
+
+// AssignmentStatus is where one student stands on one assignment.
+type AssignmentStatus struct {
+	StudentID    string     `json:"student_id"`
+	AssignmentID string     `json:"assignment_id"`
+	Status       string     `json:"status"`
+	DueAt        *time.Time `json:"due_at,omitempty"`
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty"`
+	Excused      bool       `json:"excused,omitempty"`
+}
+
+// ValidAssignmentStatus reports whether s is one of the assignment statuses.
+func ValidAssignmentStatus(s string) bool {
+	switch s {
+	case AssignmentNotYetDue, AssignmentOnTime, AssignmentLate, AssignmentMissing:
+		return true
+	}
+	return false
+}
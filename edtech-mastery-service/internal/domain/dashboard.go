@@ -1,11 +1,18 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type TeacherDashboard struct {
 	ClassID           string         `json:"class_id"`
 	CompletionRate    float64        `json:"completion_rate"`
 	AverageScore      *float64       `json:"average_score,omitempty"`
+	// LateCount and MissingCount are assigned, not excused (student, assignment) pairs submitted after their due date,
+	// and past it without a submission.
+	LateCount         int64          `json:"late_count"`
+	MissingCount      int64          `json:"missing_count"`
 	AtRiskStudents    []AtRiskStudent `json:"at_risk_students"`
 	RecentActivity    []RecentActivity `json:"recent_activity"`
 }
@@ -13,23 +20,46 @@ type TeacherDashboard struct {
 type AtRiskStudent struct {
 	StudentID string   `json:"student_id"`
 	Reasons   []string `json:"reasons"`
+	// Evidence is what each reason's rule found, keyed by reason.
+	Evidence map[string]json.RawMessage `json:"evidence,omitempty"`
+	// Status is each reason's flag status (open or acknowledged), keyed by reason.
+	Status map[string]string `json:"status"`
+	// FirstFlaggedAt is when the student's earliest open flag was raised.
+	FirstFlaggedAt time.Time `json:"first_flagged_at"`
 }
 
 type RecentActivity struct {
 	EventType   string    `json:"event_type"`
 	StudentID   string    `json:"student_id"`
 	AssignmentID string   `json:"assignment_id"`
+	OccurredAt  time.Time `json:"occurred_at"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
 type StudentMasteryView struct {
 	StudentID string             `json:"student_id"`
 	Mastery   []StandardMastery  `json:"mastery"`
+	// Tree and Uncataloged are set for ?view=tree: mastery rolled up the catalog hierarchy, and the standards not in the catalog.
+	Tree        []*MasteryNode `json:"tree,omitempty"`
+	Uncataloged []string       `json:"uncataloged,omitempty"`
 }
 
 type StandardMastery struct {
-	StandardID   string  `json:"standard_id"`
-	MasteryScore float64 `json:"mastery_score"`
+	StandardID     string     `json:"standard_id"`
+	MasteryScore   float64    `json:"mastery_score"`
+	Band           string     `json:"band"`
+	Model          string     `json:"model"`
+	EvidenceCount  int        `json:"evidence_count"`
+	LastEvidenceAt *time.Time `json:"last_evidence_at,omitempty"`
+}
+
+// StandardEvidenceView explains a student's mastery of one standard: the current score and every
+// piece of evidence that produced it, in the order it was applied.
+type StandardEvidenceView struct {
+	StudentID  string            `json:"student_id"`
+	StandardID string            `json:"standard_id"`
+	Current    *StandardMastery  `json:"current,omitempty"`
+	Evidence   []MasteryEvidence `json:"evidence"`
 }
 
 type StudentTimeline struct {
@@ -39,8 +69,17 @@ type StudentTimeline struct {
 }
 
 type TimelineEvent struct {
+	EventID     string    `json:"event_id"`
 	EventType   string    `json:"event_type"`
 	AssignmentID string   `json:"assignment_id"`
 	Score       *float64  `json:"score,omitempty"`
+	Grade       string    `json:"grade,omitempty"`
+	NormalizedScore *float64 `json:"normalized_score,omitempty"`
+	// Corrects is the original event_id a GRADE_UPDATED or EVENT_RETRACTED refers to.
+	Corrects    string    `json:"corrects,omitempty"`
+	// Retracted and CorrectedScore describe later corrections of this event; the event itself is left as received.
+	Retracted   bool      `json:"retracted,omitempty"`
+	CorrectedScore *float64 `json:"corrected_score,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
 	CreatedAt   time.Time `json:"created_at"`
 }
@@ -1,25 +1,81 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 const (
-	EventTypeAssignmentAssigned = "ASSIGNMENT_ASSIGNED"
-	EventTypeSubmissionCreated  = "SUBMISSION_CREATED"
-	EventTypeSubmissionGraded   = "SUBMISSION_GRADED"
+	EventTypeAssignmentAssigned  = "ASSIGNMENT_ASSIGNED"
+	EventTypeSubmissionCreated   = "SUBMISSION_CREATED"
+	EventTypeSubmissionGraded    = "SUBMISSION_GRADED"
+	EventTypeItemResponded       = "ITEM_RESPONDED"
+	EventTypeGradeUpdated        = "GRADE_UPDATED"
+	EventTypeEventRetracted      = "EVENT_RETRACTED"
+	EventTypeAssignmentExcused   = "ASSIGNMENT_EXCUSED"
+	EventTypeAssignmentUnexcused = "ASSIGNMENT_UNEXCUSED"
 )
 
 // IncomingEvent is the API payload for POST /events
 type IncomingEvent struct {
-	EventID      string    `json:"event_id"`
-	Source       string    `json:"source"`
-	Timestamp    time.Time `json:"timestamp"`
-	StudentID    string    `json:"student_id"`
-	ClassID      string    `json:"class_id"`
-	AssignmentID string    `json:"assignment_id"`
-	StandardIDs  []string  `json:"standard_ids"`
-	Score        *float64  `json:"score,omitempty"`
-	RubricTags   []string  `json:"rubric_tags,omitempty"`
-	Type         string    `json:"type,omitempty"` // optional: ASSIGNMENT_ASSIGNED, SUBMISSION_CREATED, SUBMISSION_GRADED
+	EventID        string    `json:"event_id"`
+	Source         string    `json:"source"`
+	Timestamp      time.Time `json:"timestamp"`
+	StudentID      string    `json:"student_id"`
+	ClassID        string    `json:"class_id"`
+	AssignmentID   string    `json:"assignment_id"`
+	StandardIDs    []string  `json:"standard_ids"`
+	Score          *float64  `json:"score,omitempty"`
+	PointsPossible *float64  `json:"points_possible,omitempty"`
+	Scale          string    `json:"scale,omitempty"` // optional: percentage (default), points, proficiency_4, letter, pass_fail
+	Grade          string    `json:"grade,omitempty"` // letter or pass/fail grade, instead of score
+	// Deprecated: RubricTags are stored as labels but never scored; send RubricScores for per-criterion evidence.
+	RubricTags     []string  `json:"rubric_tags,omitempty"`
+	Type           string    `json:"type,omitempty"` // optional: ASSIGNMENT_ASSIGNED, SUBMISSION_CREATED, SUBMISSION_GRADED, ITEM_RESPONDED; required for GRADE_UPDATED, EVENT_RETRACTED, ASSIGNMENT_EXCUSED, ASSIGNMENT_UNEXCUSED
+	// NormalizedScore (0..1) is derived by validation from the score or grade and its scale; client values are overwritten.
+	NormalizedScore *float64 `json:"normalized_score,omitempty"`
+	// RubricScores are per-criterion scores of a graded event; each criterion evidences only its own standards.
+	RubricScores []RubricScore `json:"rubric_scores,omitempty"`
+	// StandardWeights are relative weights of the standard_ids (e.g. 0.9 fractions, 0.1 geometry); unlisted standards
+	// have relative weight 1. Without them the assignment's configured weights, if any, apply.
+	StandardWeights map[string]float64 `json:"standard_weights,omitempty"`
+
+	// Item-level response (ITEM_RESPONDED): one question of an auto-graded quiz (assignment_id), aligned to standard_ids.
+	ItemID string `json:"item_id,omitempty"`
+	// Correct is shorthand for credit 1 or 0; Credit (0..1) allows partial credit and wins if both are set.
+	Correct        *bool    `json:"correct,omitempty"`
+	Credit         *float64 `json:"credit,omitempty"`
+	ResponseTimeMs *int64   `json:"response_time_ms,omitempty"`
+
+	// Corrections (GRADE_UPDATED, EVENT_RETRACTED) reference the original event; OriginalSource defaults to Source.
+	OriginalSource  string `json:"original_source,omitempty"`
+	OriginalEventID string `json:"original_event_id,omitempty"`
+
+	// DueAt is when an ASSIGNMENT_ASSIGNED assignment is due for the student; the latest assignment event's wins.
+	DueAt *time.Time `json:"due_at,omitempty"`
+}
+
+// RubricScore is one rubric criterion's score, on its own scale. StandardIDs must be among the event's standard_ids;
+// those standards get the criterion's score as mastery evidence instead of the event's overall score.
+type RubricScore struct {
+	Tag            string   `json:"tag"`
+	Score          *float64 `json:"score,omitempty"`
+	Grade          string   `json:"grade,omitempty"`
+	PointsPossible *float64 `json:"points_possible,omitempty"`
+	Scale          string   `json:"scale,omitempty"`
+	StandardIDs    []string `json:"standard_ids,omitempty"`
+	// NormalizedScore (0..1) is derived by validation, as for the event.
+	NormalizedScore *float64 `json:"normalized_score,omitempty"`
+}
+
+// EventCorrection records that a GRADE_UPDATED or EVENT_RETRACTED event was applied to the event it references
+type EventCorrection struct {
+	EventDBID         int64     `json:"event_db_id"`
+	OriginalEventDBID int64     `json:"original_event_db_id"`
+	Kind              string    `json:"kind"`
+	NormalizedScore   *float64  `json:"normalized_score,omitempty"`
+	OccurredAt        time.Time `json:"occurred_at"`
+	AppliedAt         time.Time `json:"applied_at"`
 }
 
 // Event is the stored event row (append-only)
@@ -29,9 +85,19 @@ type Event struct {
 	Source    string    `json:"source"`
 	Type      string    `json:"type"`
 	Payload   []byte    `json:"payload"`
+	OccurredAt time.Time `json:"occurred_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Outbox row statuses
+const (
+	OutboxStatusPending    = "pending"
+	OutboxStatusProcessing = "processing"
+	OutboxStatusProcessed  = "processed"
+	OutboxStatusDead       = "dead"
+	OutboxStatusDiscarded  = "discarded"
+)
+
 // OutboxItem represents a pending event for the worker
 type OutboxItem struct {
 	ID         int64
@@ -41,4 +107,50 @@ type OutboxItem struct {
 	LastError  *string
 	CreatedAt  time.Time
 	ProcessedAt *time.Time
+	DeadAt      *time.Time
+	NextAttemptAt time.Time
+	LockedBy      *string
+	LockedUntil   *time.Time
+}
+
+// DeadLetter is an outbox row that exhausted its attempts, joined with its event.
+// Payload is only populated when a single dead letter is inspected.
+type DeadLetter struct {
+	OutboxID  int64           `json:"outbox_id"`
+	EventDBID int64           `json:"event_db_id"`
+	EventID   string          `json:"event_id"`
+	Source    string          `json:"source"`
+	Type      string          `json:"type"`
+	Attempts  int             `json:"attempts"`
+	LastError *string         `json:"last_error,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	DeadAt    *time.Time      `json:"dead_at,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+}
+
+// Per-item outcomes of POST /events:batch
+const (
+	BatchStatusAccepted  = "accepted"
+	BatchStatusDuplicate = "duplicate"
+	BatchStatusInvalid   = "invalid"
+	BatchStatusError     = "error"
+)
+
+// BatchItemResult is the outcome for one item of a batch, in request order
+type BatchItemResult struct {
+	Index     int    `json:"index"`
+	EventID   string `json:"event_id,omitempty"`
+	Status    string `json:"status"`
+	Type      string `json:"type,omitempty"`
+	EventDBID int64  `json:"event_db_id,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// BatchIngestResponse is the response body for POST /events:batch
+type BatchIngestResponse struct {
+	Accepted   int               `json:"accepted"`
+	Duplicates int               `json:"duplicates"`
+	Invalid    int               `json:"invalid"`
+	Failed     int               `json:"failed"`
+	Results    []BatchItemResult `json:"results"`
 }
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// ItemResponse is a student's scored answer to one quiz item, from an ITEM_RESPONDED event
+type ItemResponse struct {
+	EventDBID      int64     `json:"event_db_id"`
+	StudentID      string    `json:"student_id"`
+	ClassID        string    `json:"class_id"`
+	AssignmentID   string    `json:"assignment_id"`
+	ItemID         string    `json:"item_id"`
+	StandardIDs    []string  `json:"standard_ids"`
+	Credit         float64   `json:"credit"`
+	ResponseTimeMs *int64    `json:"response_time_ms,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// ItemStats is the classical item analysis of one quiz item
+type ItemStats struct {
+	ItemID      string   `json:"item_id"`
+	StandardIDs []string `json:"standard_ids"`
+	Responses   int      `json:"responses"`
+	// PValue is the item's difficulty: the mean credit, so higher is easier.
+	PValue float64 `json:"p_value"`
+	// Discrimination is the corrected item-total (point-biserial) correlation; nil when it is undefined
+	// (fewer than two students, or no spread in credit or rest scores).
+	Discrimination     *float64 `json:"discrimination"`
+	MeanResponseTimeMs *float64 `json:"mean_response_time_ms,omitempty"`
+}
+
+// ItemAnalysis is the item analysis for an assignment, optionally limited to one class
+type ItemAnalysis struct {
+	AssignmentID string      `json:"assignment_id"`
+	ClassID      string      `json:"class_id,omitempty"`
+	Students     int         `json:"students"`
+	Items        []ItemStats `json:"items"`
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type StudentMastery struct {
 	StudentID   string    `json:"student_id"`
@@ -9,6 +12,103 @@ type StudentMastery struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// MasteryState is a student_mastery row with the model state carried between observations
+type MasteryState struct {
+	StudentID      string
+	StandardID     string
+	Model          string
+	Score          float64
+	EvidenceWeight float64
+	EvidenceCount  int
+	LastEvidenceAt *time.Time
+	Band           string
+}
+
+// MasteryEvidence is one graded event's contribution to a student's mastery of a standard
+type MasteryEvidence struct {
+	ID             int64     `json:"id"`
+	StudentID      string    `json:"student_id"`
+	StandardID     string    `json:"standard_id"`
+	EventDBID      int64     `json:"event_db_id"`
+	ClassID        string    `json:"class_id"`
+	AssignmentID   string    `json:"assignment_id,omitempty"`
+	ItemID         string    `json:"item_id,omitempty"`
+	Model          string    `json:"model"`
+	RawScore       *float64  `json:"raw_score,omitempty"`
+	Grade          string    `json:"grade,omitempty"`
+	Scale          string    `json:"scale,omitempty"`
+	PointsPossible *float64  `json:"points_possible,omitempty"`
+	EvidenceScore  float64   `json:"evidence_score"`
+	Criteria       []string  `json:"criteria,omitempty"` // rubric criteria behind EvidenceScore, if any
+	Weight         float64   `json:"weight"`
+	MasteryBefore  *float64  `json:"mastery_before,omitempty"`
+	MasteryAfter   float64   `json:"mastery_after"`
+	OccurredAt     time.Time `json:"occurred_at"`
+	RecordedAt     time.Time `json:"recorded_at"`
+	// VoidedBy is the GRADE_UPDATED or EVENT_RETRACTED event that withdrew this evidence; it no longer counts.
+	VoidedBy *int64 `json:"voided_by,omitempty"`
+}
+
+// MasteryModelSetting selects the mastery model for a class or a standard framework
+type MasteryModelSetting struct {
+	Scope     string          `json:"scope"`
+	ScopeID   string          `json:"scope_id"`
+	Model     string          `json:"model"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+const (
+	MasteryScopeClass     = "class"
+	MasteryScopeFramework = "framework"
+	MasteryScopeDistrict  = "district"
+)
+
+// Proficiency bands, lowest first
+const (
+	BandBeginning  = "beginning"
+	BandDeveloping = "developing"
+	BandProficient = "proficient"
+	BandAdvanced   = "advanced"
+)
+
+var masteryBands = []string{BandBeginning, BandDeveloping, BandProficient, BandAdvanced}
+
+// BandRank orders the bands (beginning = 0); it returns -1 for an unknown band.
+func BandRank(band string) int {
+	for i, b := range masteryBands {
+		if b == band {
+			return i
+		}
+	}
+	return -1
+}
+
+// MasteryBandSetting holds the band cut-points (lowest mastery score of each band) for a district or a standard framework
+type MasteryBandSetting struct {
+	Scope      string    `json:"scope"`
+	ScopeID    string    `json:"scope_id"`
+	Developing float64   `json:"developing"`
+	Proficient float64   `json:"proficient"`
+	Advanced   float64   `json:"advanced"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// BandTransition records a student's mastery of a standard moving from one band to another
+type BandTransition struct {
+	ID            int64     `json:"id"`
+	StudentID     string    `json:"student_id"`
+	StandardID    string    `json:"standard_id"`
+	ClassID       string    `json:"class_id"`
+	EventDBID     int64     `json:"event_db_id"`
+	FromBand      string    `json:"from_band"`
+	ToBand        string    `json:"to_band"`
+	MasteryBefore float64   `json:"mastery_before"`
+	MasteryAfter  float64   `json:"mastery_after"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	RecordedAt    time.Time `json:"recorded_at"`
+}
+
 type ClassRollup struct {
 	ClassID        string    `json:"class_id"`
 	CompletionRate float64   `json:"completion_rate"`
@@ -16,11 +116,56 @@ type ClassRollup struct {
 	UpdatedAt      time.Time `json:"updated_at"`
 }
 
+// RollupCounters are the incrementally maintained inputs of a ClassRollup
+type RollupCounters struct {
+	ClassID       string
+	AssignedPairs int64
+	GradedPairs   int64
+	ScoreSum      float64
+	ScoreCount    int64
+}
+
+// RollupPair is the per (student, assignment) state behind the distinct-pair counters
+type RollupPair struct {
+	ClassID      string
+	StudentID    string
+	AssignmentID string
+	Assigned     bool
+	GradedCount  int
+	// Excused pairs count in neither the assigned nor the graded pairs.
+	Excused      bool
+	// AssignedAt and DueAt come from the latest ASSIGNMENT_ASSIGNED event, SubmittedAt is the first submission
+	// (created or graded). Status is derived from them; see AssignmentStatus.
+	AssignedAt   *time.Time
+	DueAt        *time.Time
+	SubmittedAt  *time.Time
+	Status       string
+}
+
+// RollupContribution is what one processed event added to its class counters
+type RollupContribution struct {
+	EventDBID    int64
+	ClassID      string
+	StudentID    string
+	AssignmentID string
+	EventType    string
+	Score        *float64
+}
+
 type RiskFlag struct {
-	StudentID  string    `json:"student_id"`
-	ClassID    string    `json:"class_id"`
-	Reason     string    `json:"reason"`
-	ComputedAt time.Time `json:"computed_at"`
+	StudentID string          `json:"student_id"`
+	ClassID   string          `json:"class_id"`
+	Reason    string          `json:"reason"`
+	Evidence  json.RawMessage `json:"evidence,omitempty"`
+	Status    string          `json:"status"`
+	// Active is whether the rule flagged the student on the class's last recompute.
+	Active         bool       `json:"active"`
+	FirstFlaggedAt time.Time  `json:"first_flagged_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	Note           string     `json:"note,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	ComputedAt     time.Time  `json:"computed_at"`
 }
 
 const (
@@ -28,3 +173,54 @@ const (
 	RiskReasonScoreTrendDown     = "score_trend_down"
 	RiskReasonBelowMedian        = "completion_below_median"
 )
+
+// Risk flag statuses. Open and acknowledged flags are shown on the dashboard; resolved (by a teacher) and
+// auto_cleared (the rule stopped firing) flags are kept for history.
+const (
+	RiskFlagOpen         = "open"
+	RiskFlagAcknowledged = "acknowledged"
+	RiskFlagResolved     = "resolved"
+	RiskFlagAutoCleared  = "auto_cleared"
+)
+
+// RiskFlagChange is one status change in a flag's history. Actor is empty for changes made by the worker.
+type RiskFlagChange struct {
+	Reason    string          `json:"reason"`
+	Status    string          `json:"status"`
+	Actor     string          `json:"actor,omitempty"`
+	Note      string          `json:"note,omitempty"`
+	Evidence  json.RawMessage `json:"evidence,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// StudentRiskHistory is a student's flags in a class, in every status, and their changes oldest first.
+type StudentRiskHistory struct {
+	StudentID string           `json:"student_id"`
+	ClassID   string           `json:"class_id"`
+	Flags     []RiskFlag       `json:"flags"`
+	History   []RiskFlagChange `json:"history"`
+}
+
+// RiskSetting holds the at-risk rule thresholds for a class or a district (class wins)
+type RiskSetting struct {
+	Scope        string    `json:"scope"`
+	ScopeID      string    `json:"scope_id"`
+	MissingCount int       `json:"missing_count"`
+	TrendWindow  int       `json:"trend_window"`
+	MedianMargin float64   `json:"median_margin"`
+	// EnabledRules are the rules run for the scope; nil (null) runs every registered rule and empty runs none.
+	EnabledRules []string  `json:"enabled_rules"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+const (
+	RiskScopeClass    = "class"
+	RiskScopeDistrict = "district"
+)
+
+// AssignmentWeights are the relative weights of the standards an assignment assesses
+type AssignmentWeights struct {
+	AssignmentID string             `json:"assignment_id"`
+	Weights      map[string]float64 `json:"weights"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
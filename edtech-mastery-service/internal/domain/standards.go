@@ -0,0 +1,103 @@
+package domain
+
+import "time"
+
+// Standard levels, broadest first
+const (
+	StandardLevelSubject  = "subject"
+	StandardLevelDomain   = "domain"
+	StandardLevelCluster  = "cluster"
+	StandardLevelStandard = "standard"
+)
+
+var StandardLevels = []string{StandardLevelSubject, StandardLevelDomain, StandardLevelCluster, StandardLevelStandard}
+
+// StandardLevelRank orders the levels (subject = 0); it returns -1 for an unknown level.
+func StandardLevelRank(level string) int {
+	for i, l := range StandardLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+type Framework struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Version     string    `json:"version,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// StandardItem is one node of a framework's hierarchy; ID is the standard ID events carry
+type StandardItem struct {
+	ID          string    `json:"id"`
+	FrameworkID string    `json:"framework_id"`
+	ParentID    *string   `json:"parent_id,omitempty"`
+	Level       string    `json:"level"`
+	Code        string    `json:"code"`
+	Description string    `json:"description"`
+	Position    int       `json:"position"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// StandardNode is a StandardItem with its children, for the framework tree
+type StandardNode struct {
+	StandardItem
+	Children []*StandardNode `json:"children,omitempty"`
+}
+
+type FrameworkTree struct {
+	Framework
+	Standards []*StandardNode `json:"standards"`
+}
+
+// MasteryNode is a catalog node with the student's mastery rolled up from the standards below it
+type MasteryNode struct {
+	ID                string         `json:"id"`
+	Level             string         `json:"level"`
+	Code              string         `json:"code,omitempty"`
+	Description       string         `json:"description"`
+	MasteryScore      float64        `json:"mastery_score"`
+	Band              string         `json:"band,omitempty"`
+	StandardsAssessed int            `json:"standards_assessed"`
+	Children          []*MasteryNode `json:"children,omitempty"`
+}
+
+// MasteryLevelFramework is the MasteryNode level of a framework root
+const MasteryLevelFramework = "framework"
+
+// StandardAssociation is a non-hierarchical link between standards (CASE associationType other than isChildOf)
+type StandardAssociation struct {
+	ID              string `json:"id"`
+	FrameworkID     string `json:"framework_id"`
+	AssociationType string `json:"association_type"`
+	OriginID        string `json:"origin_id"`
+	DestinationID   string `json:"destination_id"`
+}
+
+// CatalogImportReport describes what a CASE package import changed (or would change, on a dry run)
+type CatalogImportReport struct {
+	FrameworkID      string           `json:"framework_id"`
+	Title            string           `json:"title"`
+	Version          string           `json:"version,omitempty"`
+	PreviousVersion  string           `json:"previous_version,omitempty"`
+	FrameworkCreated bool             `json:"framework_created"`
+	DryRun           bool             `json:"dry_run"`
+	Added            []string         `json:"added"`
+	Updated          []StandardChange `json:"updated"`
+	Unchanged        int              `json:"unchanged"`
+	// Missing lists cataloged items the package no longer contains; they are kept, as mastery may reference them.
+	Missing             []string `json:"missing"`
+	AssociationsAdded   int      `json:"associations_added"`
+	AssociationsUpdated int      `json:"associations_updated"`
+	AssociationsRemoved int      `json:"associations_removed"`
+	Warnings            []string `json:"warnings,omitempty"`
+}
+
+// StandardChange names the fields of a catalog item an import changed
+type StandardChange struct {
+	ID     string   `json:"id"`
+	Fields []string `json:"fields"`
+}
@@ -0,0 +1,174 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+	"github.com/edtech-mastery/student-progress-service/internal/storage"
+	"github.com/edtech-mastery/student-progress-service/pkg/metrics"
+)
+
+const (
+	// MaxBatchSize bounds the number of events accepted by one batch request.
+	MaxBatchSize = 5000
+	// batchTxSize is how many events share one insert transaction.
+	batchTxSize = 500
+	// maxNDJSONLine bounds a single NDJSON line (one event).
+	maxNDJSONLine = 1 << 20
+)
+
+var (
+	ErrEmptyBatch    = errors.New("batch contains no events")
+	ErrBatchTooLarge = fmt.Errorf("batch exceeds %d events", MaxBatchSize)
+)
+
+// DecodeBatch splits a batch body into raw events without validating them, so a
+// bad item can be reported on its own. NDJSON bodies are split per non-blank line;
+// otherwise the body must be a JSON array.
+func DecodeBatch(r io.Reader, ndjson bool) ([]json.RawMessage, error) {
+	var raws []json.RawMessage
+	if ndjson {
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+		for sc.Scan() {
+			line := bytes.TrimSpace(sc.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			if len(raws) == MaxBatchSize {
+				return nil, ErrBatchTooLarge
+			}
+			raws = append(raws, json.RawMessage(append([]byte(nil), line...)))
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		dec := json.NewDecoder(r)
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '[' {
+			return nil, errors.New("batch body must be a JSON array")
+		}
+		for dec.More() {
+			if len(raws) == MaxBatchSize {
+				return nil, ErrBatchTooLarge
+			}
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return nil, err
+			}
+			raws = append(raws, raw)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+	if len(raws) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	return raws, nil
+}
+
+// IngestBatch validates every item and stores the valid ones in as few transactions as possible.
+// Invalid items never reject the rest of the batch; an item the database rejects fails alone, and a failed
+// transaction only fails the items it carried.
+func (s *Service) IngestBatch(ctx context.Context, raws []json.RawMessage) *domain.BatchIngestResponse {
+	results, pending, positions := prepareBatch(raws)
+	for start := 0; start < len(pending); start += batchTxSize {
+		end := start + batchTxSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		inserted, err := s.eventRepo.InsertEvents(ctx, pending[start:end])
+		for i := start; i < end; i++ {
+			res := &results[positions[i]]
+			switch {
+			case err != nil:
+				res.Status = domain.BatchStatusError
+				res.Reason = err.Error()
+				metrics.EventsIngested.WithLabelValues(res.Type, "error").Inc()
+			case inserted[i-start].Err != nil:
+				res.Status = domain.BatchStatusError
+				res.Reason = inserted[i-start].Err.Error()
+				metrics.EventsIngested.WithLabelValues(res.Type, "error").Inc()
+			case inserted[i-start].Duplicate:
+				res.Status = domain.BatchStatusDuplicate
+				res.EventDBID = inserted[i-start].ID
+				metrics.EventsIngested.WithLabelValues(res.Type, "duplicate").Inc()
+			default:
+				res.Status = domain.BatchStatusAccepted
+				res.EventDBID = inserted[i-start].ID
+				metrics.EventsIngested.WithLabelValues(res.Type, "ok").Inc()
+			}
+		}
+	}
+
+	out := &domain.BatchIngestResponse{Results: results}
+	for _, res := range results {
+		switch res.Status {
+		case domain.BatchStatusAccepted:
+			out.Accepted++
+		case domain.BatchStatusDuplicate:
+			out.Duplicates++
+		case domain.BatchStatusInvalid:
+			out.Invalid++
+		default:
+			out.Failed++
+		}
+	}
+	return out
+}
+
+// prepareBatch decodes and validates each raw item. Invalid items get their final
+// result here; valid ones are returned as pending inserts with their result index.
+func prepareBatch(raws []json.RawMessage) (results []domain.BatchItemResult, pending []storage.NewEvent, positions []int) {
+	results = make([]domain.BatchItemResult, len(raws))
+	for i, raw := range raws {
+		results[i].Index = i
+		var in domain.IncomingEvent
+		if err := json.Unmarshal(raw, &in); err != nil {
+			results[i].Status = domain.BatchStatusInvalid
+			results[i].Reason = "invalid json: " + err.Error()
+			metrics.EventsIngested.WithLabelValues("unknown", "validation_error").Inc()
+			continue
+		}
+		results[i].EventID = in.EventID
+		eventType, err := ValidateAndSetType(&in)
+		if err != nil {
+			results[i].Status = domain.BatchStatusInvalid
+			results[i].Reason = err.Error()
+			metrics.EventsIngested.WithLabelValues("unknown", "validation_error").Inc()
+			continue
+		}
+		results[i].Type = eventType
+		payload, err := PayloadFromIncoming(&in)
+		if err != nil {
+			results[i].Status = domain.BatchStatusInvalid
+			results[i].Reason = err.Error()
+			metrics.EventsIngested.WithLabelValues(eventType, "error").Inc()
+			continue
+		}
+		pending = append(pending, storage.NewEvent{
+			EventID:         in.EventID,
+			Source:          in.Source,
+			Type:            eventType,
+			StudentID:       in.StudentID,
+			ClassID:         in.ClassID,
+			AssignmentID:    in.AssignmentID,
+			OccurredAt:      in.Timestamp,
+			NormalizedScore: in.NormalizedScore,
+			Payload:         payload,
+		})
+		positions = append(positions, i)
+	}
+	return results, pending, positions
+}
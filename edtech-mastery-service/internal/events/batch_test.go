@@ -0,0 +1,97 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func TestDecodeBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		ndjson  bool
+		want    int
+		wantErr error
+	}{
+		{name: "json array", body: `[{"event_id":"e1"},{"event_id":"e2"}]`, want: 2},
+		{name: "array with non-object item", body: `[{"event_id":"e1"}, 42]`, want: 2},
+		{name: "ndjson with blank lines", body: "{\"event_id\":\"e1\"}\n\n{\"event_id\":\"e2\"}\n", ndjson: true, want: 2},
+		{name: "ndjson keeps malformed line", body: "{\"event_id\":\"e1\"}\nnot json\n", ndjson: true, want: 2},
+		{name: "empty array", body: `[]`, wantErr: ErrEmptyBatch},
+		{name: "empty ndjson", body: "\n\n", ndjson: true, wantErr: ErrEmptyBatch},
+		{name: "too large", body: "[" + strings.Repeat(`{},`, MaxBatchSize) + "{}]", wantErr: ErrBatchTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeBatch(strings.NewReader(tt.body), tt.ndjson)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DecodeBatch() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeBatch() error = %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("DecodeBatch() = %d items, want %d", len(got), tt.want)
+			}
+		})
+	}
+
+	if _, err := DecodeBatch(strings.NewReader(`{"event_id":"e1"}`), false); err == nil {
+		t.Error("DecodeBatch() accepted a non-array body")
+	}
+}
+
+func TestPrepareBatch(t *testing.T) {
+	raws := []json.RawMessage{
+		json.RawMessage(`{"event_id":"e1","source":"s1","timestamp":"2025-02-13T12:00:00Z","student_id":"st1","class_id":"c1","assignment_id":"a1","standard_ids":["std1"],"score":90}`),
+		json.RawMessage(`not json`),
+		json.RawMessage(`{"event_id":"e3","source":"s1","class_id":"c1"}`),
+		json.RawMessage(`{"event_id":"e4","source":"s1","timestamp":"2025-02-13T12:00:00Z","student_id":"st1","class_id":"c1","assignment_id":"a1","standard_ids":["std1"],"type":"ASSIGNMENT_ASSIGNED"}`),
+	}
+	results, pending, positions := prepareBatch(raws)
+
+	if len(results) != len(raws) {
+		t.Fatalf("got %d results, want %d", len(results), len(raws))
+	}
+	wantStatus := []string{"", domain.BatchStatusInvalid, domain.BatchStatusInvalid, ""}
+	for i, want := range wantStatus {
+		if results[i].Index != i {
+			t.Errorf("results[%d].Index = %d", i, results[i].Index)
+		}
+		if results[i].Status != want {
+			t.Errorf("results[%d].Status = %q, want %q", i, results[i].Status, want)
+		}
+	}
+	if results[2].EventID != "e3" || results[2].Reason == "" {
+		t.Errorf("invalid item should keep event_id and reason, got %+v", results[2])
+	}
+	if len(pending) != 2 || positions[0] != 0 || positions[1] != 3 {
+		t.Fatalf("pending positions = %v, want [0 3]", positions)
+	}
+	if pending[0].Type != domain.EventTypeSubmissionGraded || pending[1].Type != domain.EventTypeAssignmentAssigned {
+		t.Errorf("pending types = %q, %q", pending[0].Type, pending[1].Type)
+	}
+}
+
+func TestPrepareBatchOversizedItem(t *testing.T) {
+	// An identifier too long for its column is rejected on its own instead of failing the insert of its chunk.
+	valid := func(id string) json.RawMessage {
+		return json.RawMessage(`{"event_id":"` + id + `","source":"s1","timestamp":"2025-02-13T12:00:00Z","student_id":"st1","class_id":"c1","assignment_id":"a1","standard_ids":["std1"],"score":90}`)
+	}
+	raws := []json.RawMessage{valid("e1"), valid(strings.Repeat("x", MaxIDLength+1)), valid("e3")}
+	results, pending, positions := prepareBatch(raws)
+
+	if results[1].Status != domain.BatchStatusInvalid || !strings.Contains(results[1].Reason, "event_id") {
+		t.Errorf("oversized item = %+v, want invalid naming event_id", results[1])
+	}
+	if len(pending) != 2 || positions[0] != 0 || positions[1] != 2 {
+		t.Errorf("pending positions = %v, want [0 2]", positions)
+	}
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+	"github.com/edtech-mastery/student-progress-service/internal/mastery"
+)
+
+var ErrNotCorrectable = errors.New("event cannot be corrected")
+
+// retractable are the event types EVENT_RETRACTED may void.
+var retractable = map[string]bool{
+	domain.EventTypeAssignmentAssigned:  true,
+	domain.EventTypeSubmissionCreated:   true,
+	domain.EventTypeSubmissionGraded:    true,
+	domain.EventTypeItemResponded:       true,
+	domain.EventTypeAssignmentExcused:   true,
+	domain.EventTypeAssignmentUnexcused: true,
+}
+
+// applyCorrection applies a GRADE_UPDATED or EVENT_RETRACTED event to the projections of the event it references.
+// The correction is first recorded in event_corrections, which keeps the audit trail and makes reprocessing a
+// no-op. The original must already be processed; until it is, the correction fails and is retried.
+func (p *Processor) applyCorrection(ctx context.Context, tx pgx.Tx, ev loadedEvent) error {
+	repo := p.corrections.WithTx(tx)
+	orig, status, err := repo.LockOriginal(ctx, ev.in.OriginalSource, ev.in.OriginalEventID)
+	if err != nil {
+		return err
+	}
+	if orig == nil {
+		return fmt.Errorf("original event %s/%s not found", ev.in.OriginalSource, ev.in.OriginalEventID)
+	}
+	if status != domain.OutboxStatusProcessed {
+		return fmt.Errorf("original event %d not processed yet (%s)", orig.ID, status)
+	}
+	origIn, err := PayloadToIncoming(orig.Payload)
+	if err != nil {
+		return err
+	}
+	if err := checkCorrectable(ev.event.Type, orig.Type, ev.in, origIn); err != nil {
+		return err
+	}
+
+	before, err := repo.State(ctx, orig.ID)
+	if err != nil {
+		return err
+	}
+	c := domain.EventCorrection{EventDBID: ev.event.ID, OriginalEventDBID: orig.ID, Kind: ev.event.Type, OccurredAt: ev.in.Timestamp}
+	if ev.event.Type == domain.EventTypeGradeUpdated {
+		c.NormalizedScore = ev.in.NormalizedScore
+	}
+	applied, err := repo.Insert(ctx, c)
+	if err != nil || !applied || before.Retracted {
+		// Already applied, or the original is void and stays so.
+		return err
+	}
+
+	rev := mastery.Revision{EventDBID: ev.event.ID, At: ev.in.Timestamp, OriginalDBID: orig.ID, Original: origIn}
+	if ev.event.Type == domain.EventTypeEventRetracted {
+		if err := p.rollups.RetractEvent(ctx, tx, orig.ID); err != nil {
+			return err
+		}
+		if err := p.items.RetractResponse(ctx, tx, orig.ID); err != nil {
+			return err
+		}
+		return p.mastery.ReviseEvidence(ctx, tx, rev)
+	}
+
+	after, err := repo.State(ctx, orig.ID)
+	if err != nil {
+		return err
+	}
+	if after.LatestUpdate != ev.event.ID {
+		// A later regrade is already in effect; this one stays on record only.
+		return nil
+	}
+	rev.Corrected = correctedEvent(origIn, ev.in)
+	if err := p.rollups.CorrectScore(ctx, tx, orig.ID, *rev.Corrected.NormalizedScore); err != nil {
+		return err
+	}
+	return p.mastery.ReviseEvidence(ctx, tx, rev)
+}
+
+// checkCorrectable reports whether a correction of type kind may apply to an original of type origType.
+// A correction is about the same student and class as its original.
+func checkCorrectable(kind, origType string, in, orig *domain.IncomingEvent) error {
+	switch {
+	case kind == domain.EventTypeGradeUpdated && origType != domain.EventTypeSubmissionGraded:
+		return fmt.Errorf("%w: GRADE_UPDATED applies to SUBMISSION_GRADED, not %s", ErrNotCorrectable, origType)
+	case kind == domain.EventTypeEventRetracted && !retractable[origType]:
+		return fmt.Errorf("%w: %s cannot be retracted", ErrNotCorrectable, origType)
+	case in.StudentID != orig.StudentID || in.ClassID != orig.ClassID:
+		return fmt.Errorf("%w: correction is for student %s in class %s, original for %s in %s",
+			ErrNotCorrectable, in.StudentID, in.ClassID, orig.StudentID, orig.ClassID)
+	}
+	return nil
+}
+
+// correctedEvent is the original graded event with the regrade's score. The standards, weights and assignment
+// stay the original's; rubric scores are replaced only if the regrade carries them.
+func correctedEvent(orig, update *domain.IncomingEvent) *domain.IncomingEvent {
+	c := *orig
+	c.Score, c.Grade, c.Scale, c.PointsPossible = update.Score, update.Grade, update.Scale, update.PointsPossible
+	c.NormalizedScore = update.NormalizedScore
+	if len(update.RubricScores) > 0 {
+		c.RubricScores = update.RubricScores
+	} else if update.Score != nil || update.Grade != "" {
+		// A new overall grade supersedes the original's per-criterion scores.
+		c.RubricScores = nil
+	}
+	return &c
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func TestCheckCorrectable(t *testing.T) {
+	orig := &domain.IncomingEvent{StudentID: "st1", ClassID: "c1"}
+	tests := []struct {
+		name     string
+		kind     string
+		origType string
+		in       domain.IncomingEvent
+		wantErr  bool
+	}{
+		{name: "regrade graded", kind: domain.EventTypeGradeUpdated, origType: domain.EventTypeSubmissionGraded, in: *orig},
+		{name: "regrade submission", kind: domain.EventTypeGradeUpdated, origType: domain.EventTypeSubmissionCreated, in: *orig, wantErr: true},
+		{name: "regrade item response", kind: domain.EventTypeGradeUpdated, origType: domain.EventTypeItemResponded, in: *orig, wantErr: true},
+		{name: "retract graded", kind: domain.EventTypeEventRetracted, origType: domain.EventTypeSubmissionGraded, in: *orig},
+		{name: "retract assigned", kind: domain.EventTypeEventRetracted, origType: domain.EventTypeAssignmentAssigned, in: *orig},
+		{name: "retract item response", kind: domain.EventTypeEventRetracted, origType: domain.EventTypeItemResponded, in: *orig},
+		{name: "retract regrade", kind: domain.EventTypeEventRetracted, origType: domain.EventTypeGradeUpdated, in: *orig, wantErr: true},
+		{name: "other student", kind: domain.EventTypeEventRetracted, origType: domain.EventTypeSubmissionGraded, in: domain.IncomingEvent{StudentID: "st2", ClassID: "c1"}, wantErr: true},
+		{name: "other class", kind: domain.EventTypeGradeUpdated, origType: domain.EventTypeSubmissionGraded, in: domain.IncomingEvent{StudentID: "st1", ClassID: "c2"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCorrectable(tt.kind, tt.origType, &tt.in, orig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrNotCorrectable) {
+				t.Errorf("err = %v, want ErrNotCorrectable", err)
+			}
+		})
+	}
+}
+
+func TestCorrectedEvent(t *testing.T) {
+	orig := &domain.IncomingEvent{
+		EventID: "e0", AssignmentID: "a1", StandardIDs: []string{"std1", "std2"},
+		Score: ptrFloat64(0.4), NormalizedScore: ptrFloat64(0.4),
+		StandardWeights: map[string]float64{"std1": 2},
+		RubricScores:    []domain.RubricScore{{Tag: "r1", Score: ptrFloat64(0.4), StandardIDs: []string{"std1"}}},
+	}
+
+	t.Run("overall score replaces rubric", func(t *testing.T) {
+		c := correctedEvent(orig, &domain.IncomingEvent{Grade: "B", NormalizedScore: ptrFloat64(0.8)})
+		if c.EventID != "e0" || c.AssignmentID != "a1" || len(c.StandardIDs) != 2 || c.StandardWeights["std1"] != 2 {
+			t.Errorf("original fields not kept: %+v", c)
+		}
+		if c.Score != nil || c.Grade != "B" || *c.NormalizedScore != 0.8 {
+			t.Errorf("score = %v grade = %q normalized = %v", c.Score, c.Grade, *c.NormalizedScore)
+		}
+		if c.RubricScores != nil {
+			t.Errorf("RubricScores = %v, want nil", c.RubricScores)
+		}
+		if *orig.NormalizedScore != 0.4 || len(orig.RubricScores) != 1 {
+			t.Errorf("original modified: %+v", orig)
+		}
+	})
+
+	t.Run("rubric regrade", func(t *testing.T) {
+		rubric := []domain.RubricScore{{Tag: "r1", Score: ptrFloat64(0.9), StandardIDs: []string{"std1"}}}
+		c := correctedEvent(orig, &domain.IncomingEvent{RubricScores: rubric, NormalizedScore: ptrFloat64(0.9)})
+		if len(c.RubricScores) != 1 || *c.RubricScores[0].Score != 0.9 || *c.NormalizedScore != 0.9 {
+			t.Errorf("corrected = %+v", c)
+		}
+	})
+}
@@ -2,53 +2,197 @@ package events
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 
 	"github.com/edtech-mastery/student-progress-service/internal/domain"
+	"github.com/edtech-mastery/student-progress-service/internal/items"
 	"github.com/edtech-mastery/student-progress-service/internal/mastery"
+	"github.com/edtech-mastery/student-progress-service/internal/queue"
 	"github.com/edtech-mastery/student-progress-service/internal/risk"
 	"github.com/edtech-mastery/student-progress-service/internal/rollups"
 	"github.com/edtech-mastery/student-progress-service/internal/storage"
+	"github.com/edtech-mastery/student-progress-service/pkg/metrics"
 )
 
+// unitOfWork runs fn in one transaction; *storage.UnitOfWork in production.
+type unitOfWork interface {
+	Do(ctx context.Context, fn func(tx pgx.Tx) error) error
+}
+
 type Processor struct {
-	eventRepo *storage.EventRepo
-	mastery   *mastery.Service
-	rollups   *rollups.Service
-	risk      *risk.Service
+	uow         unitOfWork
+	eventRepo   *storage.EventRepo
+	queue       *queue.Queue
+	mastery     *mastery.Service
+	rollups     *rollups.Service
+	risk        *risk.Service
+	items       *items.Service
+	corrections *storage.CorrectionsRepo
 }
 
-func NewProcessor(eventRepo *storage.EventRepo, mastery *mastery.Service, rollups *rollups.Service, risk *risk.Service) *Processor {
+func NewProcessor(uow *storage.UnitOfWork, eventRepo *storage.EventRepo, q *queue.Queue, mastery *mastery.Service, rollups *rollups.Service, risk *risk.Service, items *items.Service, corrections *storage.CorrectionsRepo) *Processor {
 	return &Processor{
-		eventRepo: eventRepo,
-		mastery:   mastery,
-		rollups:   rollups,
-		risk:      risk,
+		uow:         uow,
+		eventRepo:   eventRepo,
+		queue:       q,
+		mastery:     mastery,
+		rollups:     rollups,
+		risk:        risk,
+		items:       items,
+		corrections: corrections,
 	}
 }
 
-func (p *Processor) Process(ctx context.Context, eventDBID int64) error {
-	event, err := p.eventRepo.GetEventByID(ctx, eventDBID)
-	if err != nil || event == nil {
-		return err
+// loadedEvent is a claimed outbox item with its decoded event.
+type loadedEvent struct {
+	item  domain.OutboxItem
+	event *domain.Event
+	in    *domain.IncomingEvent
+}
+
+// ProcessBatch processes claimed items one class at a time. For each class a single
+// transaction applies every event's updates (mastery, rollup counters), recomputes the
+// class's rollups and risk flags once, and marks the items processed, so a crash never
+// leaves half-applied projections. An event that fails on its own is rolled back to its
+// savepoint without affecting the others.
+//
+// The returned map holds the error for each outbox ID that was not marked processed;
+// the caller is responsible for marking those failed.
+func (p *Processor) ProcessBatch(ctx context.Context, items []domain.OutboxItem) map[int64]error {
+	errs := make(map[int64]error, len(items))
+	var classes []string
+	byClass := make(map[string][]loadedEvent)
+	for _, item := range items {
+		ev, err := p.load(ctx, item)
+		if err != nil {
+			errs[item.ID] = err
+			continue
+		}
+		classID := ev.in.ClassID
+		if _, ok := byClass[classID]; !ok {
+			classes = append(classes, classID)
+		}
+		byClass[classID] = append(byClass[classID], ev)
+	}
+
+	for _, classID := range classes {
+		p.processClass(ctx, classID, byClass[classID], errs)
+	}
+	return errs
+}
+
+// processClass applies one class's events in a single transaction (see ProcessBatch) and records in errs the
+// error of every event that was not marked processed. If the transaction fails as a whole, including before it
+// starts, every event without an error of its own gets the transaction's.
+func (p *Processor) processClass(ctx context.Context, classID string, evs []loadedEvent, errs map[int64]error) {
+	err := p.uow.Do(ctx, func(tx pgx.Tx) error {
+		var applied []domain.OutboxItem
+		for _, ev := range evs {
+			err := storage.Savepoint(ctx, tx, func(sp pgx.Tx) error {
+				return p.apply(ctx, sp, ev)
+			})
+			if err != nil {
+				errs[ev.item.ID] = err
+				continue
+			}
+			applied = append(applied, ev.item)
+		}
+		if len(applied) == 0 {
+			return nil
+		}
+		if classID != "" {
+			metrics.ClassRecomputes.WithLabelValues("performed").Inc()
+			metrics.ClassRecomputes.WithLabelValues("coalesced").Add(float64(len(applied) - 1))
+			if err := p.recomputeClass(ctx, tx, classID); err != nil {
+				return fmt.Errorf("recompute class %s: %w", classID, err)
+			}
+		}
+		for _, item := range applied {
+			if err := p.queue.MarkProcessedTx(ctx, tx, item); err != nil {
+				return fmt.Errorf("mark outbox %d processed: %w", item.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for _, ev := range evs {
+			if _, ok := errs[ev.item.ID]; !ok {
+				errs[ev.item.ID] = err
+			}
+		}
+	}
+}
+
+// MarkOverdue moves (student, assignment) pairs whose due date has passed at now without a submission to missing,
+// then recomputes each affected class's rollups and risk flags in the same transaction. It returns how many pairs
+// became missing. Classes that fail are skipped and reported in the error; the next sweep retries them.
+func (p *Processor) MarkOverdue(ctx context.Context, now time.Time) (int64, error) {
+	classes, err := p.rollups.ClassesWithOverdue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	var marked int64
+	var errs []error
+	for _, classID := range classes {
+		var n int64
+		err := p.uow.Do(ctx, func(tx pgx.Tx) error {
+			var err error
+			if n, err = p.rollups.MarkMissing(ctx, tx, classID, now); err != nil || n == 0 {
+				return err
+			}
+			return p.recomputeClass(ctx, tx, classID)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("class %s: %w", classID, err))
+			continue
+		}
+		marked += n
+	}
+	return marked, errors.Join(errs...)
+}
+
+func (p *Processor) load(ctx context.Context, item domain.OutboxItem) (loadedEvent, error) {
+	event, err := p.eventRepo.GetEventByID(ctx, item.EventDBID)
+	if err != nil {
+		return loadedEvent{}, err
+	}
+	if event == nil {
+		return loadedEvent{}, fmt.Errorf("event %d not found", item.EventDBID)
 	}
 	in, err := PayloadToIncoming(event.Payload)
 	if err != nil {
-		return err
+		return loadedEvent{}, err
 	}
-	classID := in.ClassID
-	switch event.Type {
+	return loadedEvent{item: item, event: event, in: in}, nil
+}
+
+// apply runs the updates that depend on the single event.
+func (p *Processor) apply(ctx context.Context, tx pgx.Tx, ev loadedEvent) error {
+	switch ev.event.Type {
 	case domain.EventTypeSubmissionGraded:
-		if err := p.mastery.UpdateFromGradedEvent(ctx, in); err != nil {
+		if err := p.mastery.UpdateFromGradedEvent(ctx, tx, ev.event.ID, ev.in); err != nil {
 			return err
 		}
-	}
-	if classID != "" {
-		if err := p.rollups.RecomputeForClass(ctx, classID); err != nil {
+	case domain.EventTypeItemResponded:
+		if err := p.items.RecordResponse(ctx, tx, ev.event.ID, ev.in); err != nil {
 			return err
 		}
-		if err := p.risk.RecomputeForClass(ctx, classID); err != nil {
+		if err := p.mastery.UpdateFromGradedEvent(ctx, tx, ev.event.ID, ev.in); err != nil {
 			return err
 		}
+	case domain.EventTypeGradeUpdated, domain.EventTypeEventRetracted:
+		return p.applyCorrection(ctx, tx, ev)
+	}
+	return p.rollups.ApplyEvent(ctx, tx, ev.event.ID, ev.event.Type, ev.in)
+}
+
+func (p *Processor) recomputeClass(ctx context.Context, tx pgx.Tx, classID string) error {
+	if err := p.rollups.RecomputeForClass(ctx, tx, classID); err != nil {
+		return err
 	}
-	return nil
+	return p.risk.RecomputeForClass(ctx, tx, classID)
 }
@@ -0,0 +1,33 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+// failingUoW fails before running fn, as when the pool cannot begin a transaction.
+type failingUoW struct{ err error }
+
+func (u failingUoW) Do(context.Context, func(pgx.Tx) error) error { return u.err }
+
+func TestProcessClassBeginFailure(t *testing.T) {
+	beginErr := errors.New("begin: connection refused")
+	p := &Processor{uow: failingUoW{err: beginErr}}
+	evs := []loadedEvent{
+		{item: domain.OutboxItem{ID: 1}},
+		{item: domain.OutboxItem{ID: 2}},
+	}
+	errs := map[int64]error{}
+	p.processClass(context.Background(), "c1", evs, errs)
+
+	for _, ev := range evs {
+		if !errors.Is(errs[ev.item.ID], beginErr) {
+			t.Errorf("errs[%d] = %v, want the begin error so the item is marked failed", ev.item.ID, errs[ev.item.ID])
+		}
+	}
+}
@@ -3,6 +3,7 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"math"
 
 	"github.com/edtech-mastery/student-progress-service/internal/domain"
 	"github.com/edtech-mastery/student-progress-service/internal/storage"
@@ -28,7 +29,17 @@ func (s *Service) Ingest(ctx context.Context, in *domain.IncomingEvent) (eventTy
 		metrics.EventsIngested.WithLabelValues(eventType, "error").Inc()
 		return "", 0, err
 	}
-	eventDBID, err = s.eventRepo.InsertEvent(ctx, in.EventID, in.Source, eventType, payload)
+	eventDBID, err = s.eventRepo.InsertEvent(ctx, storage.NewEvent{
+		EventID:         in.EventID,
+		Source:          in.Source,
+		Type:            eventType,
+		StudentID:       in.StudentID,
+		ClassID:         in.ClassID,
+		AssignmentID:    in.AssignmentID,
+		OccurredAt:      in.Timestamp,
+		NormalizedScore: in.NormalizedScore,
+		Payload:         payload,
+	})
 	if err != nil {
 		metrics.EventsIngested.WithLabelValues(eventType, "error").Inc()
 		return "", 0, err
@@ -42,5 +53,10 @@ func PayloadToIncoming(payload []byte) (*domain.IncomingEvent, error) {
 	if err := json.Unmarshal(payload, &in); err != nil {
 		return nil, err
 	}
+	if in.NormalizedScore == nil && in.Score != nil {
+		// Stored before score scales: a percentage, clamped as it was then.
+		n := math.Max(0, math.Min(100, *in.Score)) / 100
+		in.NormalizedScore = &n
+	}
 	return &in, nil
 }
@@ -4,43 +4,277 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"time"
+	"unicode/utf8"
 
 	"github.com/edtech-mastery/student-progress-service/internal/domain"
+	"github.com/edtech-mastery/student-progress-service/internal/mastery"
+	"github.com/edtech-mastery/student-progress-service/internal/scales"
 )
 
+// MaxFutureSkew is how far ahead of the server clock an event timestamp may be before it is rejected.
+const MaxFutureSkew = 5 * time.Minute
+
 var (
 	ErrInvalidEventType = errors.New("invalid event type")
 	ErrMissingFields    = errors.New("missing required fields")
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+	ErrInvalidScore     = errors.New("invalid score")
+	ErrInvalidRubric    = errors.New("invalid rubric scores")
+	ErrInvalidResponse  = errors.New("invalid item response")
+	ErrFieldTooLong     = fmt.Errorf("field longer than %d characters", MaxIDLength)
 )
 
+// MaxIDLength is the length of the VARCHAR columns identifiers are stored in.
+const MaxIDLength = 255
+
 var validTypes = map[string]bool{
-	domain.EventTypeAssignmentAssigned: true,
-	domain.EventTypeSubmissionCreated:  true,
-	domain.EventTypeSubmissionGraded:   true,
+	domain.EventTypeAssignmentAssigned:  true,
+	domain.EventTypeSubmissionCreated:   true,
+	domain.EventTypeSubmissionGraded:    true,
+	domain.EventTypeItemResponded:       true,
+	domain.EventTypeGradeUpdated:        true,
+	domain.EventTypeEventRetracted:      true,
+	domain.EventTypeAssignmentExcused:   true,
+	domain.EventTypeAssignmentUnexcused: true,
 }
 
 func ValidateAndSetType(in *domain.IncomingEvent) (eventType string, err error) {
 	if in.EventID == "" || in.Source == "" || in.StudentID == "" || in.ClassID == "" {
 		return "", fmt.Errorf("%w: event_id, source, student_id, class_id required", ErrMissingFields)
 	}
+	if in.Timestamp.IsZero() {
+		return "", fmt.Errorf("%w: timestamp required", ErrMissingFields)
+	}
+	if err := checkIDLengths(in); err != nil {
+		return "", err
+	}
+	if in.Timestamp.After(time.Now().Add(MaxFutureSkew)) {
+		return "", fmt.Errorf("%w: %s is more than %s in the future", ErrInvalidTimestamp, in.Timestamp.Format(time.RFC3339), MaxFutureSkew)
+	}
+	switch {
+	case in.DueAt != nil && in.Type != "" && in.Type != domain.EventTypeAssignmentAssigned:
+		return "", fmt.Errorf("%w: due_at is only for ASSIGNMENT_ASSIGNED", ErrInvalidEventType)
+	case in.Type == domain.EventTypeGradeUpdated || in.Type == domain.EventTypeEventRetracted:
+		return in.Type, validateCorrection(in)
+	case in.OriginalEventID != "":
+		return "", fmt.Errorf("%w: original_event_id requires type GRADE_UPDATED or EVENT_RETRACTED", ErrInvalidEventType)
+	case in.Type == domain.EventTypeAssignmentExcused || in.Type == domain.EventTypeAssignmentUnexcused:
+		return in.Type, validateExcusal(in)
+	}
 	if in.Type != "" && validTypes[in.Type] {
 		// Client-provided type must be consistent (e.g. GRADED must have score)
-		if in.Type == domain.EventTypeSubmissionGraded && in.Score == nil {
-			return "", fmt.Errorf("%w: SUBMISSION_GRADED requires score", ErrInvalidEventType)
+		if in.Type == domain.EventTypeSubmissionGraded && !hasScore(in) {
+			return "", fmt.Errorf("%w: SUBMISSION_GRADED requires score, grade or rubric_scores", ErrInvalidEventType)
 		}
-		return in.Type, nil
+		eventType = in.Type
+	} else {
+		switch {
+		case in.ItemID != "":
+			eventType = domain.EventTypeItemResponded
+		case in.DueAt != nil && !hasScore(in):
+			eventType = domain.EventTypeAssignmentAssigned
+		case len(in.StandardIDs) > 0 && hasScore(in):
+			eventType = domain.EventTypeSubmissionGraded
+		case in.AssignmentID != "" && !hasScore(in) && len(in.StandardIDs) > 0:
+			eventType = domain.EventTypeSubmissionCreated
+		case in.AssignmentID != "" && len(in.StandardIDs) > 0:
+			eventType = domain.EventTypeAssignmentAssigned
+		default:
+			return "", fmt.Errorf("%w: cannot infer type from payload", ErrInvalidEventType)
+		}
+	}
+	if in.DueAt != nil {
+		if eventType != domain.EventTypeAssignmentAssigned {
+			return "", fmt.Errorf("%w: due_at is only for ASSIGNMENT_ASSIGNED", ErrInvalidEventType)
+		}
+		if in.AssignmentID == "" {
+			return "", fmt.Errorf("%w: due_at requires assignment_id", ErrMissingFields)
+		}
+	}
+	if eventType == domain.EventTypeItemResponded {
+		return eventType, normalizeItemResponse(in)
+	}
+	if err := normalizeRubric(in); err != nil {
+		return "", err
+	}
+	if err := validateWeights(in); err != nil {
+		return "", err
+	}
+	if err := normalizeScore(in); err != nil {
+		return "", err
+	}
+	return eventType, nil
+}
+
+// checkIDLengths rejects identifiers that would not fit their columns, so the insert cannot fail on them.
+func checkIDLengths(in *domain.IncomingEvent) error {
+	fields := []struct{ name, value string }{
+		{"event_id", in.EventID}, {"source", in.Source}, {"student_id", in.StudentID}, {"class_id", in.ClassID},
+		{"assignment_id", in.AssignmentID}, {"item_id", in.ItemID},
+		{"original_event_id", in.OriginalEventID}, {"original_source", in.OriginalSource},
+	}
+	for _, std := range in.StandardIDs {
+		fields = append(fields, struct{ name, value string }{"standard_ids", std})
+	}
+	for _, f := range fields {
+		if utf8.RuneCountInString(f.value) > MaxIDLength {
+			return fmt.Errorf("%w: %s", ErrFieldTooLong, f.name)
+		}
+	}
+	return nil
+}
+
+func hasScore(in *domain.IncomingEvent) bool {
+	return in.Score != nil || in.Grade != "" || len(in.RubricScores) > 0
+}
+
+// normalizeScore sets in.NormalizedScore from the score or grade on the event's declared scale,
+// rejecting values that are not on it. An event scored only by rubric criteria gets their mean.
+func normalizeScore(in *domain.IncomingEvent) error {
+	in.NormalizedScore = nil
+	if in.Score == nil && in.Grade == "" {
+		if len(in.RubricScores) > 0 {
+			var sum float64
+			for _, rs := range in.RubricScores {
+				sum += *rs.NormalizedScore
+			}
+			n := sum / float64(len(in.RubricScores))
+			in.NormalizedScore = &n
+		}
+		return nil
+	}
+	n, err := normalize(in.Score, in.Grade, in.Scale, in.PointsPossible)
+	if err != nil {
+		return err
+	}
+	in.NormalizedScore = &n
+	return nil
+}
+
+// validateCorrection checks a GRADE_UPDATED or EVENT_RETRACTED event. Whether the original exists and can be
+// corrected is only known when the worker applies it.
+func validateCorrection(in *domain.IncomingEvent) error {
+	in.NormalizedScore = nil
+	if in.OriginalEventID == "" {
+		return fmt.Errorf("%w: %s requires original_event_id", ErrMissingFields, in.Type)
+	}
+	if in.OriginalSource == "" {
+		in.OriginalSource = in.Source
 	}
+	if in.OriginalSource == in.Source && in.OriginalEventID == in.EventID {
+		return fmt.Errorf("%w: %s cannot reference itself", ErrInvalidEventType, in.Type)
+	}
+	if in.Type == domain.EventTypeEventRetracted {
+		return nil
+	}
+	if !hasScore(in) {
+		return fmt.Errorf("%w: GRADE_UPDATED requires score, grade or rubric_scores", ErrInvalidEventType)
+	}
+	if err := normalizeRubric(in); err != nil {
+		return err
+	}
+	return normalizeScore(in)
+}
+
+// validateExcusal checks an ASSIGNMENT_EXCUSED or ASSIGNMENT_UNEXCUSED event. It excuses one student from one
+// assignment, so carries no score.
+func validateExcusal(in *domain.IncomingEvent) error {
+	in.NormalizedScore = nil
+	if in.AssignmentID == "" {
+		return fmt.Errorf("%w: %s requires assignment_id", ErrMissingFields, in.Type)
+	}
+	if hasScore(in) {
+		return fmt.Errorf("%w: %s cannot carry a score", ErrInvalidEventType, in.Type)
+	}
+	return nil
+}
+
+// normalizeItemResponse checks an ITEM_RESPONDED event and sets its NormalizedScore to the item credit.
+func normalizeItemResponse(in *domain.IncomingEvent) error {
+	in.NormalizedScore = nil
+	if in.AssignmentID == "" || in.ItemID == "" {
+		return fmt.Errorf("%w: ITEM_RESPONDED requires assignment_id and item_id", ErrMissingFields)
+	}
+	credit := in.Credit
 	switch {
-	case len(in.StandardIDs) > 0 && in.Score != nil:
-		eventType = domain.EventTypeSubmissionGraded
-	case in.AssignmentID != "" && in.Score == nil && len(in.StandardIDs) > 0:
-		eventType = domain.EventTypeSubmissionCreated
-	case in.AssignmentID != "" && len(in.StandardIDs) > 0:
-		eventType = domain.EventTypeAssignmentAssigned
+	case credit != nil:
+		if math.IsNaN(*credit) || *credit < 0 || *credit > 1 {
+			return fmt.Errorf("%w: credit %v is not between 0 and 1", ErrInvalidScore, *credit)
+		}
+	case in.Correct != nil:
+		c := 0.0
+		if *in.Correct {
+			c = 1
+		}
+		credit = &c
 	default:
-		return "", fmt.Errorf("%w: cannot infer type from payload", ErrInvalidEventType)
+		return fmt.Errorf("%w: ITEM_RESPONDED requires correct or credit", ErrMissingFields)
 	}
-	return eventType, nil
+	if in.ResponseTimeMs != nil && *in.ResponseTimeMs < 0 {
+		return fmt.Errorf("%w: response_time_ms must not be negative", ErrInvalidResponse)
+	}
+	n := *credit
+	in.NormalizedScore = &n
+	return nil
+}
+
+// normalizeRubric checks the rubric criteria and sets each one's NormalizedScore from its own scale.
+func normalizeRubric(in *domain.IncomingEvent) error {
+	tagged := make(map[string]bool, len(in.StandardIDs))
+	for _, std := range in.StandardIDs {
+		tagged[std] = true
+	}
+	seen := make(map[string]bool, len(in.RubricScores))
+	for i := range in.RubricScores {
+		rs := &in.RubricScores[i]
+		rs.NormalizedScore = nil
+		if rs.Tag == "" {
+			return fmt.Errorf("%w: rubric_scores[%d] has no tag", ErrInvalidRubric, i)
+		}
+		if seen[rs.Tag] {
+			return fmt.Errorf("%w: duplicate criterion %q", ErrInvalidRubric, rs.Tag)
+		}
+		seen[rs.Tag] = true
+		if rs.Score == nil && rs.Grade == "" {
+			return fmt.Errorf("%w: criterion %q requires score or grade", ErrInvalidRubric, rs.Tag)
+		}
+		for _, std := range rs.StandardIDs {
+			if !tagged[std] {
+				return fmt.Errorf("%w: criterion %q maps to %s, which is not in standard_ids", ErrInvalidRubric, rs.Tag, std)
+			}
+		}
+		n, err := normalize(rs.Score, rs.Grade, rs.Scale, rs.PointsPossible)
+		if err != nil {
+			return fmt.Errorf("criterion %q: %w", rs.Tag, err)
+		}
+		rs.NormalizedScore = &n
+	}
+	return nil
+}
+
+// validateWeights checks the event's standard_weights refer to its standard_ids.
+func validateWeights(in *domain.IncomingEvent) error {
+	for std := range in.StandardWeights {
+		if !slices.Contains(in.StandardIDs, std) {
+			return fmt.Errorf("%w: %s is not in standard_ids", mastery.ErrInvalidWeights, std)
+		}
+	}
+	return mastery.ValidateWeights(in.StandardWeights)
+}
+
+func normalize(score *float64, grade, scale string, pointsPossible *float64) (float64, error) {
+	sc, err := scales.For(scale, pointsPossible)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidScore, err)
+	}
+	n, err := sc.Normalize(score, grade)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidScore, err)
+	}
+	return n, nil
 }
 
 func PayloadFromIncoming(in *domain.IncomingEvent) ([]byte, error) {
@@ -1,6 +1,8 @@
 package events
 
 import (
+	"errors"
+	"math"
 	"testing"
 	"time"
 
@@ -52,6 +54,78 @@ func TestValidateAndSetType(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "missing_timestamp",
+			in: domain.IncomingEvent{
+				EventID: "e1", Source: "s1",
+				StudentID: "st1", ClassID: "c1", AssignmentID: "a1", StandardIDs: []string{"std1"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "timestamp_far_in_future",
+			in: domain.IncomingEvent{
+				EventID: "e1", Source: "s1", Timestamp: now.Add(time.Hour),
+				StudentID: "st1", ClassID: "c1", AssignmentID: "a1", StandardIDs: []string{"std1"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "timestamp_within_clock_skew",
+			in: domain.IncomingEvent{
+				EventID: "e1", Source: "s1", Timestamp: now.Add(time.Minute),
+				StudentID: "st1", ClassID: "c1", AssignmentID: "a1", StandardIDs: []string{"std1"},
+			},
+			want:    domain.EventTypeSubmissionCreated,
+			wantErr: false,
+		},
+		{
+			name: "backfilled_old_timestamp",
+			in: domain.IncomingEvent{
+				EventID: "e1", Source: "s1", Timestamp: now.AddDate(-1, 0, 0),
+				StudentID: "st1", ClassID: "c1", AssignmentID: "a1", StandardIDs: []string{"std1"},
+				Score: ptrFloat64(70),
+			},
+			want:    domain.EventTypeSubmissionGraded,
+			wantErr: false,
+		},
+		{
+			name: "letter_grade_inferred_graded",
+			in: domain.IncomingEvent{
+				EventID: "e1", Source: "s1", Timestamp: now,
+				StudentID: "st1", ClassID: "c1", AssignmentID: "a1", StandardIDs: []string{"std1"},
+				Scale: "letter", Grade: "B+",
+			},
+			want:    domain.EventTypeSubmissionGraded,
+			wantErr: false,
+		},
+		{
+			name: "score_above_percentage_scale",
+			in: domain.IncomingEvent{
+				EventID: "e1", Source: "s1", Timestamp: now,
+				StudentID: "st1", ClassID: "c1", AssignmentID: "a1", StandardIDs: []string{"std1"},
+				Score: ptrFloat64(120),
+			},
+			wantErr: true,
+		},
+		{
+			name: "score_above_points_possible",
+			in: domain.IncomingEvent{
+				EventID: "e1", Source: "s1", Timestamp: now,
+				StudentID: "st1", ClassID: "c1", AssignmentID: "a1", StandardIDs: []string{"std1"},
+				Score: ptrFloat64(21), PointsPossible: ptrFloat64(20),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown_scale",
+			in: domain.IncomingEvent{
+				EventID: "e1", Source: "s1", Timestamp: now,
+				StudentID: "st1", ClassID: "c1", AssignmentID: "a1", StandardIDs: []string{"std1"},
+				Score: ptrFloat64(3), Scale: "ten_point",
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing_student_id",
 			in: domain.IncomingEvent{
@@ -75,4 +149,274 @@ func TestValidateAndSetType(t *testing.T) {
 	}
 }
 
+func TestValidateSetsNormalizedScore(t *testing.T) {
+	tests := []struct {
+		name string
+		in   domain.IncomingEvent
+		want *float64
+	}{
+		{name: "percentage by default", in: domain.IncomingEvent{Score: ptrFloat64(85)}, want: ptrFloat64(0.85)},
+		{name: "points possible", in: domain.IncomingEvent{Score: ptrFloat64(15), PointsPossible: ptrFloat64(20)}, want: ptrFloat64(0.75)},
+		{name: "proficiency", in: domain.IncomingEvent{Score: ptrFloat64(3), Scale: "proficiency_4"}, want: ptrFloat64(0.75)},
+		{name: "letter grade", in: domain.IncomingEvent{Grade: "b", Scale: "letter"}, want: ptrFloat64(0.75)},
+		{name: "pass", in: domain.IncomingEvent{Grade: "pass", Scale: "pass_fail"}, want: ptrFloat64(1)},
+		{name: "client value overwritten", in: domain.IncomingEvent{Score: ptrFloat64(50), NormalizedScore: ptrFloat64(0.9)}, want: ptrFloat64(0.5)},
+		{name: "ungraded", in: domain.IncomingEvent{NormalizedScore: ptrFloat64(0.9)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.in
+			in.EventID, in.Source, in.Timestamp = "e1", "s1", time.Now()
+			in.StudentID, in.ClassID, in.AssignmentID, in.StandardIDs = "st1", "c1", "a1", []string{"std1"}
+			if _, err := ValidateAndSetType(&in); err != nil {
+				t.Fatal(err)
+			}
+			if (in.NormalizedScore == nil) != (tt.want == nil) || (tt.want != nil && *in.NormalizedScore != *tt.want) {
+				t.Errorf("NormalizedScore = %v, want %v", in.NormalizedScore, tt.want)
+			}
+		})
+	}
+}
+
 func ptrFloat64(f float64) *float64 { return &f }
+
+func TestValidateRubricScores(t *testing.T) {
+	criterion := func(tag string, score float64, stds ...string) domain.RubricScore {
+		return domain.RubricScore{Tag: tag, Score: ptrFloat64(score), Scale: "proficiency_4", StandardIDs: stds}
+	}
+	tests := []struct {
+		name      string
+		score     *float64
+		rubric    []domain.RubricScore
+		tags      []string
+		wantScore float64
+		wantErr   error
+	}{
+		{
+			name:      "overall score kept",
+			score:     ptrFloat64(80),
+			rubric:    []domain.RubricScore{criterion("claims", 4, "std1"), criterion("conventions", 2, "std2")},
+			wantScore: 0.8,
+		},
+		{
+			name:      "rubric only uses the criteria mean",
+			rubric:    []domain.RubricScore{criterion("claims", 4, "std1"), criterion("conventions", 2, "std2")},
+			wantScore: 0.75,
+		},
+		{name: "missing tag", rubric: []domain.RubricScore{criterion("", 3, "std1")}, wantErr: ErrInvalidRubric},
+		{name: "duplicate tag", rubric: []domain.RubricScore{criterion("claims", 3), criterion("claims", 2)}, wantErr: ErrInvalidRubric},
+		{name: "no score", rubric: []domain.RubricScore{{Tag: "claims"}}, wantErr: ErrInvalidRubric},
+		{name: "untagged standard", rubric: []domain.RubricScore{criterion("claims", 3, "std9")}, wantErr: ErrInvalidRubric},
+		{name: "off scale", rubric: []domain.RubricScore{criterion("claims", 5, "std1")}, wantErr: ErrInvalidScore},
+		{
+			name:      "deprecated rubric_tags accepted and ignored",
+			score:     ptrFloat64(80),
+			rubric:    []domain.RubricScore{criterion("claims", 4, "std1")},
+			tags:      []string{"claims", "conventions"},
+			wantScore: 0.8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := domain.IncomingEvent{
+				EventID: "e1", Source: "s1", Timestamp: time.Now(),
+				StudentID: "st1", ClassID: "c1", AssignmentID: "a1", StandardIDs: []string{"std1", "std2"},
+				Score: tt.score, RubricScores: tt.rubric, RubricTags: tt.tags,
+			}
+			typ, err := ValidateAndSetType(&in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if typ != domain.EventTypeSubmissionGraded {
+				t.Errorf("type = %s, want %s", typ, domain.EventTypeSubmissionGraded)
+			}
+			if in.NormalizedScore == nil || math.Abs(*in.NormalizedScore-tt.wantScore) > 1e-9 {
+				t.Errorf("NormalizedScore = %v, want %v", in.NormalizedScore, tt.wantScore)
+			}
+			for _, rs := range in.RubricScores {
+				if rs.NormalizedScore == nil || *rs.NormalizedScore != *rs.Score/4 {
+					t.Errorf("criterion %s NormalizedScore = %v", rs.Tag, rs.NormalizedScore)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateStandardWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		wantErr bool
+	}{
+		{name: "relative weights", weights: map[string]float64{"std1": 0.9, "std2": 0.1}},
+		{name: "partial", weights: map[string]float64{"std2": 0.5}},
+		{name: "untagged standard", weights: map[string]float64{"std9": 1}, wantErr: true},
+		{name: "negative", weights: map[string]float64{"std1": -0.5}, wantErr: true},
+		{name: "all zero", weights: map[string]float64{"std1": 0, "std2": 0}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := domain.IncomingEvent{
+				EventID: "e1", Source: "s1", Timestamp: time.Now(),
+				StudentID: "st1", ClassID: "c1", AssignmentID: "a1", StandardIDs: []string{"std1", "std2"},
+				Score: ptrFloat64(90), StandardWeights: tt.weights,
+			}
+			if _, err := ValidateAndSetType(&in); (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateItemResponded(t *testing.T) {
+	yes, no := true, false
+	fast, negative := int64(800), int64(-5)
+	tests := []struct {
+		name       string
+		in         domain.IncomingEvent
+		wantCredit float64
+		wantErr    error
+	}{
+		{name: "correct", in: domain.IncomingEvent{ItemID: "q1", Correct: &yes}, wantCredit: 1},
+		{name: "incorrect", in: domain.IncomingEvent{ItemID: "q1", Correct: &no}, wantCredit: 0},
+		{name: "partial credit wins", in: domain.IncomingEvent{ItemID: "q1", Correct: &yes, Credit: ptrFloat64(0.5)}, wantCredit: 0.5},
+		{name: "explicit type", in: domain.IncomingEvent{Type: domain.EventTypeItemResponded, ItemID: "q1", Credit: ptrFloat64(0.25)}, wantCredit: 0.25},
+		{name: "explicit type without item", in: domain.IncomingEvent{Type: domain.EventTypeItemResponded, Correct: &yes}, wantErr: ErrMissingFields},
+		{name: "no credit", in: domain.IncomingEvent{ItemID: "q1"}, wantErr: ErrMissingFields},
+		{name: "credit above 1", in: domain.IncomingEvent{ItemID: "q1", Credit: ptrFloat64(2)}, wantErr: ErrInvalidScore},
+		{name: "response time", in: domain.IncomingEvent{ItemID: "q1", Correct: &yes, ResponseTimeMs: &fast}, wantCredit: 1},
+		{name: "negative response time", in: domain.IncomingEvent{ItemID: "q1", Correct: &yes, ResponseTimeMs: &negative}, wantErr: ErrInvalidResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.in
+			in.EventID, in.Source, in.Timestamp = "e1", "s1", time.Now()
+			in.StudentID, in.ClassID, in.AssignmentID, in.StandardIDs = "st1", "c1", "quiz1", []string{"std1"}
+			typ, err := ValidateAndSetType(&in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if typ != domain.EventTypeItemResponded {
+				t.Errorf("type = %s, want %s", typ, domain.EventTypeItemResponded)
+			}
+			if in.NormalizedScore == nil || *in.NormalizedScore != tt.wantCredit {
+				t.Errorf("NormalizedScore = %v, want %v", in.NormalizedScore, tt.wantCredit)
+			}
+		})
+	}
+}
+
+func TestValidateCorrections(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         domain.IncomingEvent
+		wantSource string
+		wantScore  *float64
+		wantErr    error
+	}{
+		{name: "retraction", in: domain.IncomingEvent{Type: domain.EventTypeEventRetracted, OriginalEventID: "e0"}, wantSource: "s1"},
+		{name: "retraction from other source", in: domain.IncomingEvent{Type: domain.EventTypeEventRetracted, OriginalSource: "lms", OriginalEventID: "e0"}, wantSource: "lms"},
+		{name: "regrade", in: domain.IncomingEvent{Type: domain.EventTypeGradeUpdated, OriginalEventID: "e0", Score: ptrFloat64(60)}, wantSource: "s1", wantScore: ptrFloat64(0.6)},
+		{name: "retraction drops score", in: domain.IncomingEvent{Type: domain.EventTypeEventRetracted, OriginalEventID: "e0", Score: ptrFloat64(60)}, wantSource: "s1"},
+		{name: "no original", in: domain.IncomingEvent{Type: domain.EventTypeEventRetracted}, wantErr: ErrMissingFields},
+		{name: "references itself", in: domain.IncomingEvent{Type: domain.EventTypeEventRetracted, OriginalEventID: "e1"}, wantErr: ErrInvalidEventType},
+		{name: "regrade without score", in: domain.IncomingEvent{Type: domain.EventTypeGradeUpdated, OriginalEventID: "e0"}, wantErr: ErrInvalidEventType},
+		{name: "regrade off scale", in: domain.IncomingEvent{Type: domain.EventTypeGradeUpdated, OriginalEventID: "e0", Score: ptrFloat64(130)}, wantErr: ErrInvalidScore},
+		{name: "original on graded event", in: domain.IncomingEvent{OriginalEventID: "e0", StandardIDs: []string{"std1"}, Score: ptrFloat64(60)}, wantErr: ErrInvalidEventType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.in
+			in.EventID, in.Source, in.Timestamp = "e1", "s1", time.Now()
+			in.StudentID, in.ClassID = "st1", "c1"
+			typ, err := ValidateAndSetType(&in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if typ != tt.in.Type {
+				t.Errorf("type = %s, want %s", typ, tt.in.Type)
+			}
+			if in.OriginalSource != tt.wantSource {
+				t.Errorf("OriginalSource = %q, want %q", in.OriginalSource, tt.wantSource)
+			}
+			if (in.NormalizedScore == nil) != (tt.wantScore == nil) || (tt.wantScore != nil && *in.NormalizedScore != *tt.wantScore) {
+				t.Errorf("NormalizedScore = %v, want %v", in.NormalizedScore, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestValidateExcusal(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      domain.IncomingEvent
+		wantErr error
+	}{
+		{name: "excused", in: domain.IncomingEvent{Type: domain.EventTypeAssignmentExcused, AssignmentID: "a1"}},
+		{name: "un-excused", in: domain.IncomingEvent{Type: domain.EventTypeAssignmentUnexcused, AssignmentID: "a1"}},
+		{name: "standards are not needed", in: domain.IncomingEvent{Type: domain.EventTypeAssignmentExcused, AssignmentID: "a1", StandardIDs: []string{"std1"}}},
+		{name: "no assignment", in: domain.IncomingEvent{Type: domain.EventTypeAssignmentExcused}, wantErr: ErrMissingFields},
+		{name: "with score", in: domain.IncomingEvent{Type: domain.EventTypeAssignmentExcused, AssignmentID: "a1", Score: ptrFloat64(80)}, wantErr: ErrInvalidEventType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.in
+			in.EventID, in.Source, in.Timestamp = "e1", "s1", time.Now()
+			in.StudentID, in.ClassID, in.NormalizedScore = "st1", "c1", ptrFloat64(0.5)
+			typ, err := ValidateAndSetType(&in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if typ != tt.in.Type {
+				t.Errorf("type = %s, want %s", typ, tt.in.Type)
+			}
+			if in.NormalizedScore != nil {
+				t.Errorf("NormalizedScore = %v, want nil", *in.NormalizedScore)
+			}
+		})
+	}
+}
+
+func TestValidateDueAt(t *testing.T) {
+	due := time.Now().Add(7 * 24 * time.Hour)
+	tests := []struct {
+		name     string
+		in       domain.IncomingEvent
+		wantType string
+		wantErr  error
+	}{
+		{name: "assigned", in: domain.IncomingEvent{Type: domain.EventTypeAssignmentAssigned, AssignmentID: "a1", StandardIDs: []string{"std1"}}, wantType: domain.EventTypeAssignmentAssigned},
+		{name: "inferred as assigned", in: domain.IncomingEvent{AssignmentID: "a1", StandardIDs: []string{"std1"}}, wantType: domain.EventTypeAssignmentAssigned},
+		{name: "inferred without standards", in: domain.IncomingEvent{AssignmentID: "a1"}, wantType: domain.EventTypeAssignmentAssigned},
+		{name: "no assignment", in: domain.IncomingEvent{Type: domain.EventTypeAssignmentAssigned}, wantErr: ErrMissingFields},
+		{name: "on a submission", in: domain.IncomingEvent{Type: domain.EventTypeSubmissionCreated, AssignmentID: "a1"}, wantErr: ErrInvalidEventType},
+		{name: "with a score", in: domain.IncomingEvent{AssignmentID: "a1", StandardIDs: []string{"std1"}, Score: ptrFloat64(80)}, wantErr: ErrInvalidEventType},
+		{name: "on an excusal", in: domain.IncomingEvent{Type: domain.EventTypeAssignmentExcused, AssignmentID: "a1"}, wantErr: ErrInvalidEventType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.in
+			in.EventID, in.Source, in.Timestamp = "e1", "s1", time.Now()
+			in.StudentID, in.ClassID, in.DueAt = "st1", "c1", &due
+			typ, err := ValidateAndSetType(&in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if typ != tt.wantType {
+				t.Errorf("type = %s, want %s", typ, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package items
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+	"github.com/edtech-mastery/student-progress-service/internal/storage"
+)
+
+type Service struct {
+	items *storage.ItemsRepo
+}
+
+func NewService(items *storage.ItemsRepo) *Service {
+	return &Service{items: items}
+}
+
+// RecordResponse stores an ITEM_RESPONDED event's response inside tx for item analysis.
+func (s *Service) RecordResponse(ctx context.Context, tx pgx.Tx, eventDBID int64, in *domain.IncomingEvent) error {
+	if in.NormalizedScore == nil {
+		return nil
+	}
+	return s.items.WithTx(tx).InsertResponse(ctx, domain.ItemResponse{
+		EventDBID:      eventDBID,
+		StudentID:      in.StudentID,
+		ClassID:        in.ClassID,
+		AssignmentID:   in.AssignmentID,
+		ItemID:         in.ItemID,
+		StandardIDs:    in.StandardIDs,
+		Credit:         *in.NormalizedScore,
+		ResponseTimeMs: in.ResponseTimeMs,
+		OccurredAt:     in.Timestamp,
+	})
+}
+
+// RetractResponse drops a retracted ITEM_RESPONDED event's response inside tx.
+func (s *Service) RetractResponse(ctx context.Context, tx pgx.Tx, eventDBID int64) error {
+	return s.items.WithTx(tx).DeleteResponse(ctx, eventDBID)
+}
+
+// Analysis computes item statistics for the assignment from each student's latest response to each item.
+// classID may be empty to analyse every class that took the assignment.
+func (s *Service) Analysis(ctx context.Context, assignmentID, classID string) (*domain.ItemAnalysis, error) {
+	responses, err := s.items.LatestResponses(ctx, assignmentID, classID)
+	if err != nil {
+		return nil, err
+	}
+	stats, students := analyze(responses)
+	return &domain.ItemAnalysis{AssignmentID: assignmentID, ClassID: classID, Students: students, Items: stats}, nil
+}
+
+// analyze computes each item's p-value (mean credit) and discrimination: the correlation between credit on the
+// item and the student's rest score (total credit on the other items), so the item does not correlate with itself.
+// responses must hold at most one response per student and item. Items are ordered by ID.
+func analyze(responses []domain.ItemResponse) (stats []domain.ItemStats, students int) {
+	totals := make(map[string]float64)
+	byItem := make(map[string][]domain.ItemResponse)
+	for _, r := range responses {
+		totals[r.StudentID] += r.Credit
+		byItem[r.ItemID] = append(byItem[r.ItemID], r)
+	}
+
+	stats = make([]domain.ItemStats, 0, len(byItem))
+	for itemID, rs := range byItem {
+		st := domain.ItemStats{ItemID: itemID, StandardIDs: latestStandards(rs), Responses: len(rs)}
+		credit := make([]float64, len(rs))
+		rest := make([]float64, len(rs))
+		var timeSum float64
+		var timed int
+		for i, r := range rs {
+			credit[i] = r.Credit
+			rest[i] = totals[r.StudentID] - r.Credit
+			st.PValue += r.Credit
+			if r.ResponseTimeMs != nil {
+				timeSum += float64(*r.ResponseTimeMs)
+				timed++
+			}
+		}
+		st.PValue /= float64(len(rs))
+		st.Discrimination = correlation(credit, rest)
+		if timed > 0 {
+			mean := timeSum / float64(timed)
+			st.MeanResponseTimeMs = &mean
+		}
+		stats = append(stats, st)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ItemID < stats[j].ItemID })
+	return stats, len(totals)
+}
+
+// latestStandards is the item's alignment as of its most recent response.
+func latestStandards(rs []domain.ItemResponse) []string {
+	latest := rs[0]
+	for _, r := range rs[1:] {
+		if r.OccurredAt.After(latest.OccurredAt) {
+			latest = r
+		}
+	}
+	if latest.StandardIDs == nil {
+		return []string{}
+	}
+	return latest.StandardIDs
+}
+
+// correlation is the Pearson correlation of x and y, or nil if it is undefined.
+func correlation(x, y []float64) *float64 {
+	n := float64(len(x))
+	if len(x) < 2 {
+		return nil
+	}
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx, my = mx/n, my/n
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return nil
+	}
+	r := sxy / math.Sqrt(sxx*syy)
+	return &r
+}
@@ -0,0 +1,78 @@
+package items
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func ptrInt64(i int64) *int64 { return &i }
+
+func TestAnalyze(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	// credit per student for items i1..i4; i4 everyone answers correctly.
+	grid := map[string][4]float64{
+		"s1": {1, 1, 1, 1},
+		"s2": {1, 1, 0, 1},
+		"s3": {0, 1, 0, 1},
+		"s4": {0, 0, 0, 1},
+	}
+	var responses []domain.ItemResponse
+	for student, credits := range grid {
+		for i, c := range credits {
+			r := domain.ItemResponse{StudentID: student, ItemID: "i" + string(rune('1'+i)), Credit: c, OccurredAt: at}
+			if i == 0 {
+				r.StandardIDs = []string{"6.NS.1"}
+				r.ResponseTimeMs = ptrInt64(1000 + int64(1000*c))
+			}
+			responses = append(responses, r)
+		}
+	}
+
+	stats, students := analyze(responses)
+	if students != 4 {
+		t.Errorf("students = %d, want 4", students)
+	}
+	want := []struct {
+		item           string
+		pValue         float64
+		discrimination *float64
+	}{
+		{"i1", 0.5, ptr(1 / math.Sqrt2)},
+		{"i2", 0.75, ptr(0.75 / math.Sqrt(0.75*2.75))},
+		{"i3", 0.25, ptr(0.75 / math.Sqrt(0.75*2.75))},
+		{"i4", 1, nil}, // no spread in credit
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("got %d items, want %d", len(stats), len(want))
+	}
+	for i, w := range want {
+		st := stats[i]
+		if st.ItemID != w.item || st.Responses != 4 || math.Abs(st.PValue-w.pValue) > 1e-9 {
+			t.Errorf("%s: got item %s, %d responses, p %v; want p %v", w.item, st.ItemID, st.Responses, st.PValue, w.pValue)
+		}
+		switch {
+		case (st.Discrimination == nil) != (w.discrimination == nil):
+			t.Errorf("%s discrimination = %v, want %v", w.item, st.Discrimination, w.discrimination)
+		case w.discrimination != nil && math.Abs(*st.Discrimination-*w.discrimination) > 1e-9:
+			t.Errorf("%s discrimination = %v, want %v", w.item, *st.Discrimination, *w.discrimination)
+		}
+	}
+	if got := stats[0]; len(got.StandardIDs) != 1 || got.MeanResponseTimeMs == nil || *got.MeanResponseTimeMs != 1500 {
+		t.Errorf("i1 standards %v, mean response time %v; want [6.NS.1], 1500", got.StandardIDs, got.MeanResponseTimeMs)
+	}
+	if stats[1].MeanResponseTimeMs != nil {
+		t.Errorf("i2 mean response time = %v, want nil (untimed)", *stats[1].MeanResponseTimeMs)
+	}
+}
+
+func TestAnalyzeSingleStudent(t *testing.T) {
+	stats, students := analyze([]domain.ItemResponse{{StudentID: "s1", ItemID: "i1", Credit: 0.5}})
+	if students != 1 || len(stats) != 1 || stats[0].Discrimination != nil || stats[0].PValue != 0.5 {
+		t.Errorf("analyze = %+v, %d students", stats, students)
+	}
+}
+
+func ptr(f float64) *float64 { return &f }
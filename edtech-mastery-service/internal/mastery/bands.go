@@ -0,0 +1,66 @@
+package mastery
+
+import (
+	"fmt"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+// Cuts are the lowest mastery scores of the developing, proficient and advanced bands; anything below
+// Developing is beginning.
+type Cuts struct {
+	Developing float64 `json:"developing"`
+	Proficient float64 `json:"proficient"`
+	Advanced   float64 `json:"advanced"`
+}
+
+// DefaultCuts apply when neither the class's district nor the standard's framework has band settings.
+var DefaultCuts = Cuts{Developing: 0.4, Proficient: 0.7, Advanced: 0.9}
+
+func (c Cuts) Band(score float64) string {
+	switch {
+	case score >= c.Advanced:
+		return domain.BandAdvanced
+	case score >= c.Proficient:
+		return domain.BandProficient
+	case score >= c.Developing:
+		return domain.BandDeveloping
+	default:
+		return domain.BandBeginning
+	}
+}
+
+func (c Cuts) Validate() error {
+	if !(0 < c.Developing && c.Developing < c.Proficient && c.Proficient < c.Advanced && c.Advanced <= 1) {
+		return fmt.Errorf("cut-points must satisfy 0 < developing < proficient < advanced <= 1")
+	}
+	return nil
+}
+
+func cutsFromSetting(s domain.MasteryBandSetting) Cuts {
+	return Cuts{Developing: s.Developing, Proficient: s.Proficient, Advanced: s.Advanced}
+}
+
+// resolveCuts picks the cut-points for a standard: the district setting wins, then the best framework
+// setting (see frameworkMatch), then DefaultCuts.
+func resolveCuts(settings []domain.MasteryBandSetting, districtID, frameworkID, standardID string) Cuts {
+	var chosen *domain.MasteryBandSetting
+	best := -1
+	for i := range settings {
+		st := &settings[i]
+		switch st.Scope {
+		case domain.MasteryScopeDistrict:
+			if districtID != "" && st.ScopeID == districtID {
+				return cutsFromSetting(*st)
+			}
+		case domain.MasteryScopeFramework:
+			if m := frameworkMatch(st.ScopeID, frameworkID, standardID); m > best {
+				chosen, best = st, m
+			}
+		}
+	}
+	if chosen != nil {
+		return cutsFromSetting(*chosen)
+	}
+	return DefaultCuts
+}
@@ -0,0 +1,77 @@
+package mastery
+
+import (
+	"testing"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func TestCutsBand(t *testing.T) {
+	tests := []struct {
+		score float64
+		want  string
+	}{
+		{0, domain.BandBeginning},
+		{0.3999, domain.BandBeginning},
+		{0.4, domain.BandDeveloping},
+		{0.7, domain.BandProficient},
+		{0.8999, domain.BandProficient},
+		{0.9, domain.BandAdvanced},
+		{1, domain.BandAdvanced},
+	}
+	for _, tt := range tests {
+		if got := DefaultCuts.Band(tt.score); got != tt.want {
+			t.Errorf("Band(%v) = %s, want %s", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestCutsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cuts    Cuts
+		wantErr bool
+	}{
+		{name: "default", cuts: DefaultCuts},
+		{name: "advanced at 1", cuts: Cuts{Developing: 0.5, Proficient: 0.75, Advanced: 1}},
+		{name: "zero developing", cuts: Cuts{Developing: 0, Proficient: 0.7, Advanced: 0.9}, wantErr: true},
+		{name: "out of order", cuts: Cuts{Developing: 0.7, Proficient: 0.6, Advanced: 0.9}, wantErr: true},
+		{name: "equal cuts", cuts: Cuts{Developing: 0.5, Proficient: 0.5, Advanced: 0.9}, wantErr: true},
+		{name: "above 1", cuts: Cuts{Developing: 0.5, Proficient: 0.7, Advanced: 1.1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cuts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveCuts(t *testing.T) {
+	district := Cuts{Developing: 0.5, Proficient: 0.8, Advanced: 0.95}
+	framework := Cuts{Developing: 0.3, Proficient: 0.6, Advanced: 0.85}
+	settings := []domain.MasteryBandSetting{
+		{Scope: domain.MasteryScopeFramework, ScopeID: "CCSS.MATH", Developing: framework.Developing, Proficient: framework.Proficient, Advanced: framework.Advanced},
+		{Scope: domain.MasteryScopeDistrict, ScopeID: "district-1", Developing: district.Developing, Proficient: district.Proficient, Advanced: district.Advanced},
+	}
+	tests := []struct {
+		name       string
+		districtID string
+		standard   string
+		want       Cuts
+	}{
+		{name: "district wins", districtID: "district-1", standard: "CCSS.MATH.6.RP.1", want: district},
+		{name: "framework", districtID: "district-2", standard: "CCSS.MATH.6.RP.1", want: framework},
+		{name: "class without district", standard: "CCSS.MATH.6.RP.1", want: framework},
+		{name: "default", districtID: "district-2", standard: "NGSS.MS-PS1-1", want: DefaultCuts},
+		{name: "prefix stops at a segment boundary", districtID: "district-2", standard: "CCSS.MATHEMATICS.1", want: DefaultCuts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveCuts(settings, tt.districtID, "", tt.standard); got != tt.want {
+				t.Errorf("resolveCuts() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,38 +2,295 @@ package mastery
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
 
 	"github.com/edtech-mastery/student-progress-service/internal/domain"
 	"github.com/edtech-mastery/student-progress-service/internal/storage"
 )
 
-// Default: score 0..100 maps to mastery 0..1
-const maxScore = 100.0
-
 type Service struct {
-	mastery *storage.MasteryRepo
+	mastery   *storage.MasteryRepo
+	standards *storage.StandardsRepo
 }
 
-func NewService(mastery *storage.MasteryRepo) *Service {
-	return &Service{mastery: mastery}
+func NewService(mastery *storage.MasteryRepo, standards *storage.StandardsRepo) *Service {
+	return &Service{mastery: mastery, standards: standards}
 }
 
-func (s *Service) UpdateFromGradedEvent(ctx context.Context, in *domain.IncomingEvent) error {
-	if in.Score == nil || len(in.StandardIDs) == 0 {
+// UpdateFromGradedEvent adds the event's evidence (see standardScores and evidenceWeight) to each tagged standard
+// inside tx, with the mastery model of the class or the standard's framework, and records it in the standard's
+// evidence history. Evidence older than the standard's latest is replayed in time order, as corrections are.
+// The new score is banded and band changes are recorded. Standards the event already contributed to are skipped.
+func (s *Service) UpdateFromGradedEvent(ctx context.Context, tx pgx.Tx, eventDBID int64, in *domain.IncomingEvent) error {
+	scores := standardScores(in)
+	if len(scores) == 0 {
 		return nil
 	}
-	score := *in.Score
-	if score < 0 {
-		score = 0
+
+	repo := s.mastery.WithTx(tx)
+	weights, err := weightsFor(ctx, repo, in)
+	if err != nil {
+		return err
 	}
-	if score > maxScore {
-		score = maxScore
+	pol, err := s.loadPolicy(ctx, tx, in.ClassID, in.StandardIDs)
+	if err != nil {
+		return err
 	}
-	masteryScore := score / maxScore
-	for _, std := range in.StandardIDs {
-		if err := s.mastery.UpsertMastery(ctx, in.StudentID, std, masteryScore); err != nil {
+	for _, sc := range scores {
+		std := sc.StandardID
+		ev := Evidence{Score: sc.Score, Weight: evidenceWeight(weights, in.StandardIDs, std), At: in.Timestamp}
+		model, err := pol.model(std)
+		if err != nil {
+			return err
+		}
+		prev, err := repo.LockMastery(ctx, in.StudentID, std)
+		if err != nil {
+			return err
+		}
+		var history []Evidence
+		if outOfOrder(stateFromDomain(prev), ev) {
+			if history, err = activeHistory(ctx, repo, in.StudentID, std); err != nil {
+				return err
+			}
+		}
+		next := applyEvidence(model, stateFromDomain(prev), ev, history)
+		record := evidenceRecord(eventDBID, in, sc, ev, model.Name(), next.Score)
+		if prev != nil {
+			record.MasteryBefore = &prev.Score
+		}
+		applied, err := repo.InsertEvidence(ctx, record)
+		if err != nil {
+			return err
+		}
+		if !applied {
+			continue
+		}
+		state := stateToDomain(in.StudentID, std, model.Name(), next)
+		state.Band = pol.cuts(std).Band(next.Score)
+		err = saveState(ctx, repo, prev, state, domain.BandTransition{ClassID: in.ClassID, EventDBID: eventDBID, OccurredAt: in.Timestamp})
+		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// policy is how a class's evidence is scored: the model and band settings that apply to it and the catalog
+// frameworks of the standards involved.
+type policy struct {
+	classID    string
+	settings   []domain.MasteryModelSetting
+	districtID string
+	bands      []domain.MasteryBandSetting
+	frameworks map[string]string
+}
+
+func (s *Service) loadPolicy(ctx context.Context, tx pgx.Tx, classID string, standardIDs []string) (*policy, error) {
+	repo := s.mastery.WithTx(tx)
+	pol := &policy{classID: classID}
+	var err error
+	if pol.settings, err = repo.ModelSettingsFor(ctx, classID); err != nil {
+		return nil, err
+	}
+	if pol.districtID, pol.bands, err = repo.BandSettingsFor(ctx, classID); err != nil {
+		return nil, err
+	}
+	if pol.frameworks, err = s.standards.WithTx(tx).FrameworkIDs(ctx, standardIDs); err != nil {
+		return nil, err
+	}
+	return pol, nil
+}
+
+func (p *policy) model(standardID string) (Model, error) {
+	return resolveModel(p.settings, p.classID, p.frameworks[standardID], standardID)
+}
+
+func (p *policy) cuts(standardID string) Cuts {
+	return resolveCuts(p.bands, p.districtID, p.frameworks[standardID], standardID)
+}
+
+// weightsFor returns the event's standard weights, else its assignment's configured weights (nil if neither).
+func weightsFor(ctx context.Context, repo *storage.MasteryRepo, in *domain.IncomingEvent) (map[string]float64, error) {
+	if len(in.StandardWeights) > 0 || !usesAssignmentWeights(in) {
+		return in.StandardWeights, nil
+	}
+	aw, err := repo.AssignmentWeights(ctx, in.AssignmentID)
+	if err != nil || aw == nil {
+		return nil, err
+	}
+	return aw.Weights, nil
+}
+
+func evidenceRecord(eventDBID int64, in *domain.IncomingEvent, sc standardScore, ev Evidence, model string, after float64) domain.MasteryEvidence {
+	return domain.MasteryEvidence{
+		StudentID:      in.StudentID,
+		StandardID:     sc.StandardID,
+		EventDBID:      eventDBID,
+		ClassID:        in.ClassID,
+		AssignmentID:   in.AssignmentID,
+		ItemID:         in.ItemID,
+		Model:          model,
+		RawScore:       in.Score,
+		Grade:          in.Grade,
+		Scale:          in.Scale,
+		PointsPossible: in.PointsPossible,
+		EvidenceScore:  ev.Score,
+		Criteria:       sc.Criteria,
+		Weight:         ev.Weight,
+		MasteryAfter:   after,
+		OccurredAt:     ev.At,
+	}
+}
+
+// saveState stores the new mastery state and, if its band differs from prev's, records the transition;
+// t carries the class, event and timestamp of the change.
+func saveState(ctx context.Context, repo *storage.MasteryRepo, prev *domain.MasteryState, state domain.MasteryState, t domain.BandTransition) error {
+	if err := repo.SaveMastery(ctx, state); err != nil {
+		return err
+	}
+	if prev == nil || prev.Band == state.Band {
+		return nil
+	}
+	t.StudentID, t.StandardID = state.StudentID, state.StandardID
+	t.FromBand, t.ToBand = prev.Band, state.Band
+	t.MasteryBefore, t.MasteryAfter = prev.Score, state.Score
+	return repo.InsertBandTransition(ctx, t)
+}
+
+func (s *Service) ListSettings(ctx context.Context) ([]domain.MasteryModelSetting, error) {
+	return s.mastery.ListModelSettings(ctx)
+}
+
+// SaveSetting validates the model and its params before storing them, so the worker never meets a bad setting.
+func (s *Service) SaveSetting(ctx context.Context, setting domain.MasteryModelSetting) error {
+	if setting.Scope != domain.MasteryScopeClass && setting.Scope != domain.MasteryScopeFramework {
+		return fmt.Errorf("unknown scope %q", setting.Scope)
+	}
+	if _, err := NewModel(setting.Model, setting.Params); err != nil {
+		return err
+	}
+	return s.mastery.UpsertModelSetting(ctx, setting)
+}
+
+// DeleteSetting reports false if there was no setting for the scope.
+func (s *Service) DeleteSetting(ctx context.Context, scope, scopeID string) (bool, error) {
+	return s.mastery.DeleteModelSetting(ctx, scope, scopeID)
+}
+
+func (s *Service) ListBandSettings(ctx context.Context) ([]domain.MasteryBandSetting, error) {
+	return s.mastery.ListBandSettings(ctx)
+}
+
+// SaveBandSetting validates the cut-points before storing them. New cut-points apply from each student's next evidence.
+func (s *Service) SaveBandSetting(ctx context.Context, setting domain.MasteryBandSetting) error {
+	if setting.Scope != domain.MasteryScopeDistrict && setting.Scope != domain.MasteryScopeFramework {
+		return fmt.Errorf("unknown scope %q", setting.Scope)
+	}
+	if err := cutsFromSetting(setting).Validate(); err != nil {
+		return err
+	}
+	return s.mastery.UpsertBandSetting(ctx, setting)
+}
+
+// DeleteBandSetting reports false if there was no setting for the scope.
+func (s *Service) DeleteBandSetting(ctx context.Context, scope, scopeID string) (bool, error) {
+	return s.mastery.DeleteBandSetting(ctx, scope, scopeID)
+}
+
+// AssignmentWeights returns nil if the assignment has no configured weights.
+func (s *Service) AssignmentWeights(ctx context.Context, assignmentID string) (*domain.AssignmentWeights, error) {
+	return s.mastery.AssignmentWeights(ctx, assignmentID)
+}
+
+// SaveAssignmentWeights replaces the assignment's weights. They apply to graded events processed from now on.
+func (s *Service) SaveAssignmentWeights(ctx context.Context, w domain.AssignmentWeights) error {
+	if len(w.Weights) == 0 {
+		return fmt.Errorf("%w: weights required", ErrInvalidWeights)
+	}
+	if err := ValidateWeights(w.Weights); err != nil {
+		return err
+	}
+	return s.mastery.ReplaceAssignmentWeights(ctx, w)
+}
+
+// DeleteAssignmentWeights reports false if the assignment had no weights.
+func (s *Service) DeleteAssignmentWeights(ctx context.Context, assignmentID string) (bool, error) {
+	return s.mastery.DeleteAssignmentWeights(ctx, assignmentID)
+}
+
+func (s *Service) SetClassDistrict(ctx context.Context, classID, districtID string) error {
+	if districtID == "" {
+		return fmt.Errorf("district_id required")
+	}
+	return s.mastery.SetClassDistrict(ctx, classID, districtID)
+}
+
+// resolveModel picks the model for a standard: the class setting wins, then the best framework setting
+// (see frameworkMatch), then DefaultModel.
+func resolveModel(settings []domain.MasteryModelSetting, classID, frameworkID, standardID string) (Model, error) {
+	var chosen *domain.MasteryModelSetting
+	best := -1
+	for i := range settings {
+		st := &settings[i]
+		switch st.Scope {
+		case domain.MasteryScopeClass:
+			if st.ScopeID == classID {
+				return NewModel(st.Model, st.Params)
+			}
+		case domain.MasteryScopeFramework:
+			if m := frameworkMatch(st.ScopeID, frameworkID, standardID); m > best {
+				chosen, best = st, m
+			}
+		}
+	}
+	if chosen != nil {
+		return NewModel(chosen.Model, chosen.Params)
+	}
+	return NewModel(DefaultModel, nil)
+}
+
+// frameworkMatch scores how well a framework-scoped setting applies to a standard, or -1 if it does not.
+// The catalog framework of a cataloged standard (frameworkID) matches best; otherwise the longest scope ID
+// that is the standard ID or a dotted prefix of it (e.g. "CCSS.MATH" for "CCSS.MATH.6.RP.1", but not for
+// "CCSS.MATHEMATICS.1") wins.
+func frameworkMatch(scopeID, frameworkID, standardID string) int {
+	switch {
+	case frameworkID != "" && scopeID == frameworkID:
+		return math.MaxInt
+	case standardID == scopeID || strings.HasPrefix(standardID, scopeID+"."):
+		return len(scopeID)
+	default:
+		return -1
+	}
+}
+
+func stateFromDomain(s *domain.MasteryState) State {
+	if s == nil {
+		return State{}
+	}
+	st := State{Score: s.Score, Weight: s.EvidenceWeight, EvidenceCount: s.EvidenceCount}
+	if s.LastEvidenceAt != nil {
+		st.LastEvidenceAt = *s.LastEvidenceAt
+	}
+	return st
+}
+
+func stateToDomain(studentID, standardID, model string, st State) domain.MasteryState {
+	s := domain.MasteryState{
+		StudentID:      studentID,
+		StandardID:     standardID,
+		Model:          model,
+		Score:          st.Score,
+		EvidenceWeight: st.Weight,
+		EvidenceCount:  st.EvidenceCount,
+	}
+	if !st.LastEvidenceAt.IsZero() {
+		at := st.LastEvidenceAt
+		s.LastEvidenceAt = &at
+	}
+	return s
+}
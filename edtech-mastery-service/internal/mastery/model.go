@@ -0,0 +1,227 @@
+package mastery
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Model names, as stored in student_mastery.model and mastery_model_settings.model
+const (
+	ModelLastScore       = "last_score"
+	ModelMovingAverage   = "moving_average"
+	ModelDecayingAverage = "decaying_average"
+	ModelBKT             = "bkt"
+)
+
+// DefaultModel keeps the original behaviour: mastery is the latest score.
+const DefaultModel = ModelLastScore
+
+// Evidence is one observation of a student's performance on a standard.
+type Evidence struct {
+	// Score is the normalized result, 0..1.
+	Score float64
+	// Weight (0..1] is how strongly the observation bears on the standard.
+	Weight float64
+	At     time.Time
+}
+
+// State is what a model carries from one observation to the next.
+type State struct {
+	// Score is the current mastery estimate, 0..1.
+	Score float64
+	// Weight is the accumulated evidence weight; only the decaying average uses it.
+	Weight         float64
+	EvidenceCount  int
+	LastEvidenceAt time.Time
+}
+
+// Model turns a stream of evidence into a mastery score.
+type Model interface {
+	Name() string
+	// Update folds ev into prev. prev.EvidenceCount is 0 for a standard without evidence yet.
+	Update(prev State, ev Evidence) State
+}
+
+// LastScore is mastery = latest score. Weight is ignored except that zero-weight evidence changes nothing.
+type LastScore struct{}
+
+func (LastScore) Name() string { return ModelLastScore }
+
+func (LastScore) Update(prev State, ev Evidence) State {
+	next := observed(prev, ev)
+	if ev.Weight > 0 {
+		next.Score = ev.Score
+	}
+	next.Weight = 1
+	return next
+}
+
+// MovingAverage is an exponentially weighted moving average: every full-weight observation
+// moves mastery Alpha of the way towards its score. Lower-weight evidence moves it less.
+type MovingAverage struct {
+	Alpha float64 `json:"alpha"`
+}
+
+func (MovingAverage) Name() string { return ModelMovingAverage }
+
+func (m MovingAverage) Update(prev State, ev Evidence) State {
+	next := observed(prev, ev)
+	if prev.EvidenceCount == 0 {
+		next.Score = ev.Score
+	} else {
+		rate := 1 - math.Pow(1-m.Alpha, ev.Weight)
+		next.Score = prev.Score + rate*(ev.Score-prev.Score)
+	}
+	next.Weight = 1
+	return next
+}
+
+// DecayingAverage is a weighted average in which evidence loses half its weight every
+// HalfLifeDays, so recent work counts more without older work being discarded.
+type DecayingAverage struct {
+	HalfLifeDays float64 `json:"half_life_days"`
+}
+
+func (DecayingAverage) Name() string { return ModelDecayingAverage }
+
+func (m DecayingAverage) Update(prev State, ev Evidence) State {
+	next := observed(prev, ev)
+	prevWeight, evWeight := prev.Weight, ev.Weight
+	if prev.EvidenceCount == 0 {
+		prevWeight = 0
+	} else if dt := ev.At.Sub(prev.LastEvidenceAt); dt >= 0 {
+		prevWeight *= m.decay(dt)
+	} else {
+		// Late-arriving evidence is older than what we already have: decay it instead.
+		evWeight *= m.decay(-dt)
+	}
+	total := prevWeight + evWeight
+	if total <= 0 {
+		next.Score, next.Weight = prev.Score, prevWeight
+		return next
+	}
+	next.Score = (prev.Score*prevWeight + ev.Score*evWeight) / total
+	next.Weight = total
+	return next
+}
+
+func (m DecayingAverage) decay(dt time.Duration) float64 {
+	if m.HalfLifeDays <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, dt.Hours()/24/m.HalfLifeDays)
+}
+
+// BKT is Bayesian Knowledge Tracing: mastery is the probability the student has learned the
+// standard. Each observation updates it by Bayes' rule given the slip and guess rates, then
+// allows for learning (Transit). Partial credit interpolates between the correct and incorrect
+// posteriors; weight scales both the Bayesian update and the learning step.
+type BKT struct {
+	PInit    float64 `json:"p_init"`
+	PTransit float64 `json:"p_transit"`
+	PSlip    float64 `json:"p_slip"`
+	PGuess   float64 `json:"p_guess"`
+}
+
+func (BKT) Name() string { return ModelBKT }
+
+func (m BKT) Update(prev State, ev Evidence) State {
+	next := observed(prev, ev)
+	p := m.PInit
+	if prev.EvidenceCount > 0 {
+		p = prev.Score
+	}
+	pCorrect := p * (1 - m.PSlip) / (p*(1-m.PSlip) + (1-p)*m.PGuess)
+	pIncorrect := p * m.PSlip / (p*m.PSlip + (1-p)*(1-m.PGuess))
+	posterior := ev.Score*pCorrect + (1-ev.Score)*pIncorrect
+	posterior = p + ev.Weight*(posterior-p)
+	next.Score = clamp01(posterior + (1-posterior)*m.PTransit*ev.Weight)
+	next.Weight = 1
+	return next
+}
+
+// observed returns prev with the bookkeeping common to every model applied.
+func observed(prev State, ev Evidence) State {
+	next := prev
+	next.EvidenceCount++
+	if ev.At.After(prev.LastEvidenceAt) {
+		next.LastEvidenceAt = ev.At
+	}
+	return next
+}
+
+func clamp01(f float64) float64 {
+	return math.Max(0, math.Min(1, f))
+}
+
+// constructors build each model from its defaults overlaid with JSON params.
+var constructors = map[string]func(params json.RawMessage) (Model, error){
+	ModelLastScore: func(json.RawMessage) (Model, error) { return LastScore{}, nil },
+	ModelMovingAverage: func(params json.RawMessage) (Model, error) {
+		m := MovingAverage{Alpha: 0.3}
+		if err := decodeParams(params, &m); err != nil {
+			return nil, err
+		}
+		if m.Alpha <= 0 || m.Alpha > 1 {
+			return nil, fmt.Errorf("alpha must be in (0, 1], got %v", m.Alpha)
+		}
+		return m, nil
+	},
+	ModelDecayingAverage: func(params json.RawMessage) (Model, error) {
+		m := DecayingAverage{HalfLifeDays: 30}
+		if err := decodeParams(params, &m); err != nil {
+			return nil, err
+		}
+		if m.HalfLifeDays <= 0 {
+			return nil, fmt.Errorf("half_life_days must be positive, got %v", m.HalfLifeDays)
+		}
+		return m, nil
+	},
+	ModelBKT: func(params json.RawMessage) (Model, error) {
+		m := BKT{PInit: 0.3, PTransit: 0.1, PSlip: 0.1, PGuess: 0.2}
+		if err := decodeParams(params, &m); err != nil {
+			return nil, err
+		}
+		for name, p := range map[string]float64{"p_init": m.PInit, "p_transit": m.PTransit, "p_slip": m.PSlip, "p_guess": m.PGuess} {
+			if p < 0 || p > 1 {
+				return nil, fmt.Errorf("%s must be in [0, 1], got %v", name, p)
+			}
+		}
+		if m.PSlip+m.PGuess >= 1 {
+			return nil, fmt.Errorf("p_slip + p_guess must be below 1")
+		}
+		return m, nil
+	},
+}
+
+// NewModel returns the named model configured with params (a JSON object; empty means defaults).
+func NewModel(name string, params json.RawMessage) (Model, error) {
+	ctor, ok := constructors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown mastery model %q", name)
+	}
+	return ctor(params)
+}
+
+// ModelNames lists the available models.
+func ModelNames() []string {
+	names := make([]string, 0, len(constructors))
+	for name := range constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func decodeParams(params json.RawMessage, into interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, into); err != nil {
+		return fmt.Errorf("invalid model params: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package mastery
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+var day0 = time.Date(2024, 9, 2, 9, 0, 0, 0, time.UTC)
+
+func evidenceAt(days []int, scores ...float64) []Evidence {
+	out := make([]Evidence, len(scores))
+	for i, s := range scores {
+		out[i] = Evidence{Score: s, Weight: 1, At: day0.AddDate(0, 0, days[i])}
+	}
+	return out
+}
+
+func TestModels(t *testing.T) {
+	seq := evidenceAt([]int{0, 10, 20, 20}, 0.9, 0.8, 0.2, 0.85)
+	tests := []struct {
+		name     string
+		model    Model
+		evidence []Evidence
+		want     float64
+	}{
+		{name: "last score", model: LastScore{}, evidence: seq, want: 0.85},
+		{name: "moving average", model: MovingAverage{Alpha: 0.3}, evidence: seq, want: 0.7233},
+		{name: "moving average alpha 1 is last score", model: MovingAverage{Alpha: 1}, evidence: seq, want: 0.85},
+		{name: "decaying average", model: DecayingAverage{HalfLifeDays: 10}, evidence: seq, want: 0.6090909},
+		{name: "decaying average late evidence", model: DecayingAverage{HalfLifeDays: 10}, evidence: evidenceAt([]int{10, 0}, 1, 0), want: 2.0 / 3},
+		{name: "bkt correct", model: BKT{PInit: 0.3, PTransit: 0.1, PSlip: 0.1, PGuess: 0.2}, evidence: evidenceAt([]int{0}, 1), want: 0.6926829},
+		{name: "bkt sequence", model: BKT{PInit: 0.3, PTransit: 0.1, PSlip: 0.1, PGuess: 0.2}, evidence: evidenceAt([]int{0, 1, 2, 3}, 1, 1, 0, 1), want: 0.8955079},
+		{name: "bkt partial credit", model: BKT{PInit: 0.3, PTransit: 0.1, PSlip: 0.1, PGuess: 0.2}, evidence: evidenceAt([]int{0}, 0.5), want: 0.4192228},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var st State
+			for _, ev := range tt.evidence {
+				st = tt.model.Update(st, ev)
+			}
+			if math.Abs(st.Score-tt.want) > 1e-6 {
+				t.Errorf("score = %v, want %v", st.Score, tt.want)
+			}
+			if st.EvidenceCount != len(tt.evidence) {
+				t.Errorf("evidence count = %d, want %d", st.EvidenceCount, len(tt.evidence))
+			}
+		})
+	}
+}
+
+func TestZeroWeightEvidenceKeepsScore(t *testing.T) {
+	models := []Model{LastScore{}, MovingAverage{Alpha: 0.3}, DecayingAverage{HalfLifeDays: 10}, BKT{PInit: 0.3, PTransit: 0.1, PSlip: 0.1, PGuess: 0.2}}
+	for _, m := range models {
+		st := m.Update(State{}, Evidence{Score: 0.8, Weight: 1, At: day0})
+		next := m.Update(st, Evidence{Score: 0, Weight: 0, At: day0.AddDate(0, 0, 1)})
+		if math.Abs(next.Score-st.Score) > 1e-9 {
+			t.Errorf("%s: score moved from %v to %v on zero-weight evidence", m.Name(), st.Score, next.Score)
+		}
+	}
+}
+
+func TestNewModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   string
+		params  string
+		wantErr bool
+	}{
+		{name: "defaults", model: ModelBKT},
+		{name: "params", model: ModelMovingAverage, params: `{"alpha":0.5}`},
+		{name: "unknown model", model: "median", wantErr: true},
+		{name: "alpha out of range", model: ModelMovingAverage, params: `{"alpha":1.5}`, wantErr: true},
+		{name: "half life zero", model: ModelDecayingAverage, params: `{"half_life_days":0}`, wantErr: true},
+		{name: "bkt not identifiable", model: ModelBKT, params: `{"p_slip":0.5,"p_guess":0.5}`, wantErr: true},
+		{name: "bad json", model: ModelBKT, params: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewModel(tt.model, json.RawMessage(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewModel() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveModel(t *testing.T) {
+	settings := []domain.MasteryModelSetting{
+		{Scope: domain.MasteryScopeFramework, ScopeID: "CCSS", Model: ModelMovingAverage},
+		{Scope: domain.MasteryScopeFramework, ScopeID: "CCSS.MATH", Model: ModelBKT},
+		{Scope: domain.MasteryScopeClass, ScopeID: "class-1", Model: ModelDecayingAverage},
+		{Scope: domain.MasteryScopeFramework, ScopeID: "ccss-math-2010", Model: ModelLastScore},
+	}
+	tests := []struct {
+		name      string
+		classID   string
+		framework string
+		standard  string
+		want      string
+	}{
+		{name: "class wins", classID: "class-1", standard: "CCSS.MATH.6.RP.1", want: ModelDecayingAverage},
+		{name: "longest framework prefix", classID: "class-2", standard: "CCSS.MATH.6.RP.1", want: ModelBKT},
+		{name: "shorter framework prefix", classID: "class-2", standard: "CCSS.ELA.RL.1", want: ModelMovingAverage},
+		{name: "default", classID: "class-2", standard: "NGSS.MS-PS1-1", want: DefaultModel},
+		{name: "prefix stops at a segment boundary", classID: "class-2", standard: "CCSS.MATHEMATICS.1", want: ModelMovingAverage},
+		{name: "prefix without a dot does not match", classID: "class-2", standard: "CCSSX.MATH.1", want: DefaultModel},
+		{name: "scope equal to standard", classID: "class-2", standard: "CCSS.MATH", want: ModelBKT},
+		{name: "catalog framework beats prefix", classID: "class-2", framework: "ccss-math-2010", standard: "CCSS.MATH.6.RP.1", want: ModelLastScore},
+		{name: "unconfigured framework falls back to prefix", classID: "class-2", framework: "other", standard: "CCSS.MATH.6.RP.1", want: ModelBKT},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := resolveModel(settings, tt.classID, tt.framework, tt.standard)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Name() != tt.want {
+				t.Errorf("resolveModel() = %s, want %s", m.Name(), tt.want)
+			}
+		})
+	}
+}
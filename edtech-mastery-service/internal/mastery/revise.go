@@ -0,0 +1,140 @@
+package mastery
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+	"github.com/edtech-mastery/student-progress-service/internal/storage"
+)
+
+// Revision is a correction of an already processed event.
+type Revision struct {
+	// EventDBID and At identify the GRADE_UPDATED or EVENT_RETRACTED event and when it happened.
+	EventDBID    int64
+	At           time.Time
+	OriginalDBID int64
+	Original     *domain.IncomingEvent
+	// Corrected is the original with its corrected score, or nil for a retraction.
+	Corrected *domain.IncomingEvent
+}
+
+// ReviseEvidence applies r inside tx. The evidence the original contributed is voided and, for a regrade,
+// replaced by evidence from the correction dated like the original. Each affected standard's mastery is then
+// replayed from its remaining evidence with the current model, since models such as the moving average depend on
+// every observation. Standards with no evidence left lose their mastery row. Band changes are dated r.At.
+func (s *Service) ReviseEvidence(ctx context.Context, tx pgx.Tx, r Revision) error {
+	original, corrected, eventDBID := r.Original, r.Corrected, r.EventDBID
+	repo := s.mastery.WithTx(tx)
+	voided, err := repo.VoidEvidence(ctx, original.StudentID, r.OriginalDBID, eventDBID)
+	if err != nil {
+		return err
+	}
+	replacements := make(map[string]standardScore)
+	affected := voided
+	if corrected != nil {
+		for _, sc := range standardScores(corrected) {
+			replacements[sc.StandardID] = sc
+			if !slices.Contains(affected, sc.StandardID) {
+				affected = append(affected, sc.StandardID)
+			}
+		}
+	}
+	if len(affected) == 0 {
+		return nil
+	}
+
+	weights, err := weightsFor(ctx, repo, original)
+	if err != nil {
+		return err
+	}
+	pol, err := s.loadPolicy(ctx, tx, original.ClassID, affected)
+	if err != nil {
+		return err
+	}
+	for _, std := range affected {
+		model, err := pol.model(std)
+		if err != nil {
+			return err
+		}
+		prev, err := repo.LockMastery(ctx, original.StudentID, std)
+		if err != nil {
+			return err
+		}
+		history, err := activeHistory(ctx, repo, original.StudentID, std)
+		if err != nil {
+			return err
+		}
+		sc, replaced := replacements[std]
+		var ev Evidence
+		if replaced {
+			ev = Evidence{Score: sc.Score, Weight: evidenceWeight(weights, original.StandardIDs, std), At: original.Timestamp}
+			history = append(history, ev)
+		}
+		next := replay(model, history)
+
+		if replaced {
+			record := evidenceRecord(eventDBID, corrected, sc, ev, model.Name(), next.Score)
+			if prev != nil {
+				record.MasteryBefore = &prev.Score
+			}
+			if _, err := repo.InsertEvidence(ctx, record); err != nil {
+				return err
+			}
+		}
+		if len(history) == 0 {
+			if err := repo.DeleteMastery(ctx, original.StudentID, std); err != nil {
+				return err
+			}
+			continue
+		}
+		state := stateToDomain(original.StudentID, std, model.Name(), next)
+		state.Band = pol.cuts(std).Band(next.Score)
+		err = saveState(ctx, repo, prev, state, domain.BandTransition{ClassID: original.ClassID, EventDBID: eventDBID, OccurredAt: r.At})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activeHistory is the standard's evidence that has not been voided, as model input.
+func activeHistory(ctx context.Context, repo *storage.MasteryRepo, studentID, standardID string) ([]Evidence, error) {
+	active, err := repo.ActiveEvidence(ctx, studentID, standardID)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]Evidence, 0, len(active)+1)
+	for _, e := range active {
+		history = append(history, Evidence{Score: e.EvidenceScore, Weight: e.Weight, At: e.OccurredAt})
+	}
+	return history, nil
+}
+
+// outOfOrder reports whether ev is older than the latest evidence already in prev.
+func outOfOrder(prev State, ev Evidence) bool {
+	return prev.EvidenceCount > 0 && ev.At.Before(prev.LastEvidenceAt)
+}
+
+// applyEvidence adds ev to prev. Out-of-order evidence is replayed with history (the evidence already applied),
+// so the result does not depend on arrival order; history is only read in that case.
+func applyEvidence(model Model, prev State, ev Evidence, history []Evidence) State {
+	if !outOfOrder(prev, ev) {
+		return model.Update(prev, ev)
+	}
+	return replay(model, append(history, ev))
+}
+
+// replay folds evidence into a fresh state in time order; evidence at the same time keeps its order.
+func replay(model Model, history []Evidence) State {
+	sort.SliceStable(history, func(i, j int) bool { return history[i].At.Before(history[j].At) })
+	var st State
+	for _, ev := range history {
+		st = model.Update(st, ev)
+	}
+	return st
+}
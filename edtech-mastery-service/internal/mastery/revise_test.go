@@ -0,0 +1,68 @@
+package mastery
+
+import (
+	"math"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	t.Run("regrade of an older event", func(t *testing.T) {
+		// A regrade's replacement evidence is dated at the original and appended last; replay puts it back in place.
+		history := append(evidenceAt([]int{10}, 0.9), evidenceAt([]int{0}, 0.2)...)
+		st := replay(LastScore{}, history)
+		if st.Score != 0.9 || st.EvidenceCount != 2 || !st.LastEvidenceAt.Equal(day0.AddDate(0, 0, 10)) {
+			t.Errorf("state = %+v, want score 0.9 from day 10", st)
+		}
+	})
+
+	t.Run("same time keeps order", func(t *testing.T) {
+		st := replay(LastScore{}, evidenceAt([]int{5, 5}, 0.3, 0.7))
+		if st.Score != 0.7 {
+			t.Errorf("score = %v, want 0.7", st.Score)
+		}
+	})
+
+	t.Run("matches incremental update", func(t *testing.T) {
+		model := MovingAverage{Alpha: 0.5}
+		seq := evidenceAt([]int{0, 1, 2}, 1, 0, 1)
+		var want State
+		for _, ev := range seq {
+			want = model.Update(want, ev)
+		}
+		got := replay(model, []Evidence{seq[2], seq[0], seq[1]})
+		if math.Abs(got.Score-want.Score) > 1e-9 || got.EvidenceCount != 3 {
+			t.Errorf("replay = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("no evidence", func(t *testing.T) {
+		if st := replay(LastScore{}, nil); st.EvidenceCount != 0 {
+			t.Errorf("state = %+v, want empty", st)
+		}
+	})
+}
+
+func TestApplyEvidenceOutOfOrder(t *testing.T) {
+	// A backfilled event arriving last ends at the same mastery as replaying the whole history, which is what a
+	// later correction does.
+	arrivals := evidenceAt([]int{0, 4, 8, 2}, 0.9, 0.4, 0.8, 0.1)
+	models := []Model{LastScore{}, MovingAverage{Alpha: 0.3}, DecayingAverage{HalfLifeDays: 30}, BKT{PInit: 0.3, PTransit: 0.1, PSlip: 0.1, PGuess: 0.2}}
+	for _, model := range models {
+		t.Run(model.Name(), func(t *testing.T) {
+			var st State
+			var applied []Evidence
+			for _, ev := range arrivals {
+				st = applyEvidence(model, st, ev, append([]Evidence(nil), applied...))
+				applied = append(applied, ev)
+			}
+			want := replay(model, append([]Evidence(nil), arrivals...))
+			if math.Abs(st.Score-want.Score) > 1e-9 || st.EvidenceCount != want.EvidenceCount || !st.LastEvidenceAt.Equal(want.LastEvidenceAt) {
+				t.Errorf("out of order = %+v, replay = %+v", st, want)
+			}
+		})
+	}
+
+	if outOfOrder(State{}, arrivals[3]) {
+		t.Error("first evidence reported out of order")
+	}
+}
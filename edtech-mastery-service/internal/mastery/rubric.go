@@ -0,0 +1,40 @@
+package mastery
+
+import (
+	"slices"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+// standardScore is the evidence one graded event gives one standard.
+type standardScore struct {
+	StandardID string
+	Score      float64
+	// Criteria are the tags of the rubric criteria the score comes from; empty for the overall score.
+	Criteria []string
+}
+
+// standardScores splits a graded event into per-standard evidence. A standard mapped by rubric criteria
+// gets the mean of their normalized scores; every other tagged standard gets the event's overall score.
+func standardScores(in *domain.IncomingEvent) []standardScore {
+	if in.NormalizedScore == nil {
+		return nil
+	}
+	out := make([]standardScore, 0, len(in.StandardIDs))
+	for _, std := range in.StandardIDs {
+		score := standardScore{StandardID: std, Score: *in.NormalizedScore}
+		var sum float64
+		for _, rs := range in.RubricScores {
+			if rs.NormalizedScore == nil || !slices.Contains(rs.StandardIDs, std) {
+				continue
+			}
+			sum += *rs.NormalizedScore
+			score.Criteria = append(score.Criteria, rs.Tag)
+		}
+		if len(score.Criteria) > 0 {
+			score.Score = sum / float64(len(score.Criteria))
+		}
+		out = append(out, score)
+	}
+	return out
+}
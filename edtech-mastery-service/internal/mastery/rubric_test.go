@@ -0,0 +1,41 @@
+package mastery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func ptrFloat64(f float64) *float64 { return &f }
+
+func TestStandardScores(t *testing.T) {
+	essay := &domain.IncomingEvent{
+		StandardIDs:     []string{"W.1.a", "W.1.b", "L.2", "RL.1"},
+		NormalizedScore: ptrFloat64(0.7),
+		RubricScores: []domain.RubricScore{
+			{Tag: "claims", NormalizedScore: ptrFloat64(1), StandardIDs: []string{"W.1.a"}},
+			{Tag: "evidence", NormalizedScore: ptrFloat64(0.5), StandardIDs: []string{"W.1.a", "W.1.b"}},
+			{Tag: "conventions", NormalizedScore: ptrFloat64(0.25), StandardIDs: []string{"L.2"}},
+		},
+	}
+	want := []standardScore{
+		{StandardID: "W.1.a", Score: 0.75, Criteria: []string{"claims", "evidence"}},
+		{StandardID: "W.1.b", Score: 0.5, Criteria: []string{"evidence"}},
+		{StandardID: "L.2", Score: 0.25, Criteria: []string{"conventions"}},
+		{StandardID: "RL.1", Score: 0.7},
+	}
+	if got := standardScores(essay); !reflect.DeepEqual(got, want) {
+		t.Errorf("standardScores = %+v, want %+v", got, want)
+	}
+
+	plain := &domain.IncomingEvent{StandardIDs: []string{"a", "b"}, NormalizedScore: ptrFloat64(0.6)}
+	want = []standardScore{{StandardID: "a", Score: 0.6}, {StandardID: "b", Score: 0.6}}
+	if got := standardScores(plain); !reflect.DeepEqual(got, want) {
+		t.Errorf("standardScores without rubric = %+v, want %+v", got, want)
+	}
+
+	if got := standardScores(&domain.IncomingEvent{StandardIDs: []string{"a"}}); got != nil {
+		t.Errorf("ungraded = %+v, want nil", got)
+	}
+}
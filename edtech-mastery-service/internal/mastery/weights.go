@@ -0,0 +1,59 @@
+package mastery
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+var ErrInvalidWeights = errors.New("invalid standard weights")
+
+// ValidateWeights checks relative standard weights: finite, non-negative, and not all zero.
+func ValidateWeights(weights map[string]float64) error {
+	var total float64
+	for std, w := range weights {
+		if math.IsNaN(w) || math.IsInf(w, 0) || w < 0 {
+			return fmt.Errorf("%w: %s has weight %v", ErrInvalidWeights, std, w)
+		}
+		total += w
+	}
+	if len(weights) > 0 && total == 0 {
+		return fmt.Errorf("%w: all weights are zero", ErrInvalidWeights)
+	}
+	return nil
+}
+
+// evidenceWeight scales a standard's relative weight to an Evidence.Weight in 0..1: the most heavily
+// assessed standard counts fully and the others in proportion. The event's standards (standardIDs) without a
+// weight have relative weight 1 and take part in the scaling, so {"geo": 0.1} on an event tagged frac and geo
+// weighs geometry 0.1 and fractions fully. Without weights every standard counts fully.
+func evidenceWeight(weights map[string]float64, standardIDs []string, standardID string) float64 {
+	if len(weights) == 0 {
+		return 1
+	}
+	weightOf := func(std string) float64 {
+		if w, ok := weights[std]; ok {
+			return w
+		}
+		return 1
+	}
+	var heaviest float64
+	for _, v := range weights {
+		heaviest = math.Max(heaviest, v)
+	}
+	for _, std := range standardIDs {
+		heaviest = math.Max(heaviest, weightOf(std))
+	}
+	if heaviest == 0 {
+		return 1
+	}
+	return weightOf(standardID) / heaviest
+}
+
+// usesAssignmentWeights reports whether the assignment's configured weights apply to the event. They describe
+// the whole assignment, so an item response (one question, aligned to its own standards) does not use them.
+func usesAssignmentWeights(in *domain.IncomingEvent) bool {
+	return in.AssignmentID != "" && in.ItemID == ""
+}
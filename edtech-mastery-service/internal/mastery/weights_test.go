@@ -0,0 +1,94 @@
+package mastery
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func TestValidateWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		wantErr bool
+	}{
+		{name: "none", weights: nil},
+		{name: "relative", weights: map[string]float64{"frac": 0.9, "geo": 0.1}},
+		{name: "one zero", weights: map[string]float64{"frac": 3, "geo": 0}},
+		{name: "all zero", weights: map[string]float64{"frac": 0, "geo": 0}, wantErr: true},
+		{name: "negative", weights: map[string]float64{"frac": -1}, wantErr: true},
+		{name: "NaN", weights: map[string]float64{"frac": math.NaN()}, wantErr: true},
+		{name: "infinite", weights: map[string]float64{"frac": math.Inf(1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWeights(tt.weights)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidWeights)) {
+				t.Errorf("ValidateWeights(%v) = %v, wantErr %v", tt.weights, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvidenceWeight(t *testing.T) {
+	tests := []struct {
+		name      string
+		weights   map[string]float64
+		standards []string
+		std       string
+		want      float64
+	}{
+		{name: "heaviest", weights: map[string]float64{"frac": 90, "geo": 10}, standards: []string{"frac", "geo"}, std: "frac", want: 1},
+		{name: "proportional", weights: map[string]float64{"frac": 90, "geo": 10}, standards: []string{"frac", "geo"}, std: "geo", want: 1.0 / 9},
+		{name: "zero", weights: map[string]float64{"frac": 90, "none": 0}, standards: []string{"frac", "none"}, std: "none", want: 0},
+		{name: "unlisted scaled like weight 1", weights: map[string]float64{"frac": 2}, standards: []string{"frac", "geo"}, std: "geo", want: 0.5},
+		{name: "partial map keeps the listed weight", weights: map[string]float64{"geo": 0.1}, standards: []string{"frac", "geo"}, std: "geo", want: 0.1},
+		{name: "partial map unlisted counts fully", weights: map[string]float64{"geo": 0.1}, standards: []string{"frac", "geo"}, std: "frac", want: 1},
+		{name: "no weights", standards: []string{"frac"}, std: "frac", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evidenceWeight(tt.weights, tt.standards, tt.std); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("evidenceWeight(%s) = %v, want %v", tt.std, got, tt.want)
+			}
+		})
+	}
+}
+
+// A test that is 90% fractions and 10% geometry moves geometry mastery less than fractions mastery.
+func TestUsesAssignmentWeights(t *testing.T) {
+	tests := []struct {
+		name string
+		in   domain.IncomingEvent
+		want bool
+	}{
+		{name: "graded submission", in: domain.IncomingEvent{AssignmentID: "a1"}, want: true},
+		{name: "item response", in: domain.IncomingEvent{AssignmentID: "a1", ItemID: "q1", Type: domain.EventTypeItemResponded}, want: false},
+		{name: "item response without type", in: domain.IncomingEvent{AssignmentID: "a1", ItemID: "q1"}, want: false},
+		{name: "no assignment", in: domain.IncomingEvent{}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := usesAssignmentWeights(&tt.in); got != tt.want {
+				t.Errorf("usesAssignmentWeights() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeightedEvidenceMovesLightStandardLess(t *testing.T) {
+	weights := map[string]float64{"frac": 0.9, "geo": 0.1}
+	stds := []string{"frac", "geo"}
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	prev := State{Score: 0.5, Weight: 1, EvidenceCount: 3, LastEvidenceAt: at.Add(-24 * time.Hour)}
+	for _, m := range []Model{MovingAverage{Alpha: 0.3}, DecayingAverage{HalfLifeDays: 30}, BKT{PInit: 0.3, PTransit: 0.1, PSlip: 0.1, PGuess: 0.2}} {
+		frac := m.Update(prev, Evidence{Score: 1, Weight: evidenceWeight(weights, stds, "frac"), At: at})
+		geo := m.Update(prev, Evidence{Score: 1, Weight: evidenceWeight(weights, stds, "geo"), At: at})
+		if !(geo.Score-prev.Score < frac.Score-prev.Score) || geo.Score <= prev.Score {
+			t.Errorf("%s: fractions %v -> %v, geometry %v -> %v; want geometry to move less", m.Name(), prev.Score, frac.Score, prev.Score, geo.Score)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how failed outbox items are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the number of claims after which a failing item is dead-lettered.
+	MaxAttempts int
+	// BaseDelay is the wait after the first failure; it doubles with every further attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential delay.
+	MaxDelay time.Duration
+	// Jitter is the fraction (0..1) of the delay that is randomised, so items that failed together don't retry together.
+	Jitter float64
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   2 * time.Second,
+		MaxDelay:    5 * time.Minute,
+		Jitter:      0.2,
+	}
+}
+
+// Backoff returns how long to wait before retrying an item that has failed `attempts` times.
+// The delay is BaseDelay * 2^(attempts-1), capped at MaxDelay, then reduced by up to Jitter of itself.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempts-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	jitter := math.Max(0, math.Min(1, p.Jitter))
+	delay -= delay * jitter * rand.Float64()
+	return time.Duration(delay)
+}
@@ -0,0 +1,45 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+	"github.com/edtech-mastery/student-progress-service/internal/storage"
+	"github.com/edtech-mastery/student-progress-service/pkg/metrics"
+)
+
+// DeadLetters is the admin view over outbox rows that exhausted their attempts.
+type DeadLetters struct {
+	outbox *storage.OutboxRepo
+}
+
+func NewDeadLetters(outbox *storage.OutboxRepo) *DeadLetters {
+	return &DeadLetters{outbox: outbox}
+}
+
+func (d *DeadLetters) List(ctx context.Context, afterID int64, limit int) ([]domain.DeadLetter, error) {
+	return d.outbox.ListDead(ctx, afterID, limit)
+}
+
+// Get returns the dead letter with its payload, or nil if outboxID is not dead-lettered.
+func (d *DeadLetters) Get(ctx context.Context, outboxID int64) (*domain.DeadLetter, error) {
+	return d.outbox.GetDead(ctx, outboxID)
+}
+
+// Requeue makes the event claimable again with a fresh attempt budget. It reports false if outboxID is not dead-lettered.
+func (d *DeadLetters) Requeue(ctx context.Context, outboxID int64) (bool, error) {
+	ok, err := d.outbox.RequeueDead(ctx, outboxID)
+	if ok {
+		metrics.OutboxDeadLetterActions.WithLabelValues("requeue").Inc()
+	}
+	return ok, err
+}
+
+// Discard gives up on the event for good. It reports false if outboxID is not dead-lettered.
+func (d *DeadLetters) Discard(ctx context.Context, outboxID int64) (bool, error) {
+	ok, err := d.outbox.DiscardDead(ctx, outboxID)
+	if ok {
+		metrics.OutboxDeadLetterActions.WithLabelValues("discard").Inc()
+	}
+	return ok, err
+}
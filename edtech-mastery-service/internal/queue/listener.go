@@ -0,0 +1,109 @@
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/edtech-mastery/student-progress-service/internal/storage"
+	"github.com/edtech-mastery/student-progress-service/pkg/metrics"
+)
+
+const (
+	listenRetryMin = time.Second
+	listenRetryMax = 30 * time.Second
+)
+
+// Listener holds a dedicated LISTEN connection on the outbox channel and wakes idle
+// workers as soon as new events are committed. While it is disconnected, workers
+// should fall back to polling; Connected reports which mode applies.
+type Listener struct {
+	dsn       string
+	log       zerolog.Logger
+	wake      chan struct{}
+	connected atomic.Bool
+}
+
+// NewListener returns a listener able to wake up to workers idle workers per notification.
+func NewListener(dsn string, log zerolog.Logger, workers int) *Listener {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Listener{dsn: dsn, log: log, wake: make(chan struct{}, workers)}
+}
+
+// Wake receives a value whenever new outbox rows may be claimable.
+func (l *Listener) Wake() <-chan struct{} {
+	return l.wake
+}
+
+func (l *Listener) Connected() bool {
+	return l.connected.Load()
+}
+
+// Run listens until ctx is done, reconnecting with backoff when the connection drops.
+func (l *Listener) Run(ctx context.Context) {
+	retry := listenRetryMin
+	for {
+		wasConnected, err := l.listen(ctx)
+		l.setConnected(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if wasConnected {
+			retry = listenRetryMin
+		}
+		l.log.Warn().Err(err).Dur("retry_in", retry).Msg("outbox listener disconnected, workers polling")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(retry*2, listenRetryMax)
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) (wasConnected bool, err error) {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{storage.OutboxNotifyChannel}.Sanitize()); err != nil {
+		return false, err
+	}
+	l.setConnected(true)
+	l.log.Info().Str("channel", storage.OutboxNotifyChannel).Msg("outbox listener connected")
+	// Anything committed while we were disconnected was never announced.
+	l.broadcast()
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return true, err
+		}
+		l.broadcast()
+	}
+}
+
+// broadcast wakes as many idle workers as the channel has room for, without blocking.
+func (l *Listener) broadcast() {
+	for {
+		select {
+		case l.wake <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
+
+func (l *Listener) setConnected(ok bool) {
+	l.connected.Store(ok)
+	if ok {
+		metrics.WorkerListenerConnected.Set(1)
+	} else {
+		metrics.WorkerListenerConnected.Set(0)
+	}
+}
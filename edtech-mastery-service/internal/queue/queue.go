@@ -2,28 +2,76 @@ package queue
 
 import (
 	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 
 	"github.com/edtech-mastery/student-progress-service/internal/domain"
 	"github.com/edtech-mastery/student-progress-service/internal/storage"
+	"github.com/edtech-mastery/student-progress-service/pkg/metrics"
 )
 
 // Queue is the postgres-backed outbox for async event processing.
 type Queue struct {
 	outbox *storage.OutboxRepo
+	retry  RetryPolicy
+	lease  time.Duration
+}
+
+// NewQueue returns a queue that retries failed items with exponential backoff and
+// dead-letters them once they have been claimed retry.MaxAttempts times. Claimed items
+// are leased for lease; an item not marked within that time is handed to another worker.
+func NewQueue(outbox *storage.OutboxRepo, retry RetryPolicy, lease time.Duration) *Queue {
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+	return &Queue{outbox: outbox, retry: retry, lease: lease}
+}
+
+// Claim leases up to limit due items to workerID.
+func (q *Queue) Claim(ctx context.Context, workerID string, limit int) ([]domain.OutboxItem, error) {
+	return q.outbox.ClaimNext(ctx, limit, workerID, q.lease)
+}
+
+// MarkProcessed completes a claimed item. It returns storage.ErrLeaseLost if the lease was reaped first.
+func (q *Queue) MarkProcessed(ctx context.Context, item domain.OutboxItem) error {
+	return q.outbox.MarkProcessed(ctx, item.ID, leaseHolder(item))
 }
 
-func NewQueue(outbox *storage.OutboxRepo) *Queue {
-	return &Queue{outbox: outbox}
+// MarkProcessedTx completes a claimed item inside tx, so the status change commits together with
+// the projection updates made for it.
+func (q *Queue) MarkProcessedTx(ctx context.Context, tx pgx.Tx, item domain.OutboxItem) error {
+	return q.outbox.WithTx(tx).MarkProcessed(ctx, item.ID, leaseHolder(item))
 }
 
-func (q *Queue) Claim(ctx context.Context, limit int) ([]domain.OutboxItem, error) {
-	return q.outbox.ClaimNext(ctx, limit)
+// MarkFailed schedules the item for a later retry based on its attempts, or dead-letters it when they are exhausted.
+func (q *Queue) MarkFailed(ctx context.Context, item domain.OutboxItem, errMsg string) (dead bool, err error) {
+	dead, err = q.outbox.MarkFailed(ctx, item.ID, leaseHolder(item), errMsg, q.retry.MaxAttempts, q.retry.Backoff(item.Attempts))
+	if err != nil {
+		return false, err
+	}
+	if dead {
+		metrics.OutboxDeadLettered.Inc()
+	}
+	return dead, nil
 }
 
-func (q *Queue) MarkProcessed(ctx context.Context, outboxID int64) error {
-	return q.outbox.MarkProcessed(ctx, outboxID)
+// ReapExpiredLeases returns items stuck in processing past their lease to pending (or dead)
+// and reports how many were found.
+func (q *Queue) ReapExpiredLeases(ctx context.Context) (released, dead int, err error) {
+	released, dead, err = q.outbox.ReleaseExpiredLeases(ctx, q.retry.MaxAttempts)
+	if err != nil {
+		return 0, 0, err
+	}
+	metrics.OutboxStuckItems.Set(float64(released + dead))
+	metrics.OutboxLeasesReclaimed.Add(float64(released + dead))
+	metrics.OutboxDeadLettered.Add(float64(dead))
+	return released, dead, nil
 }
 
-func (q *Queue) MarkFailed(ctx context.Context, outboxID int64, errMsg string) error {
-	return q.outbox.MarkFailed(ctx, outboxID, errMsg)
+func leaseHolder(item domain.OutboxItem) string {
+	if item.LockedBy == nil {
+		return ""
+	}
+	return *item.LockedBy
 }
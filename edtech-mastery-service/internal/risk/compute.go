@@ -2,135 +2,165 @@ package risk
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/edtech-mastery/student-progress-service/internal/domain"
 	"github.com/edtech-mastery/student-progress-service/internal/storage"
 )
 
 const (
+	// MissingSubmissionsThreshold is the default number of missing assignments that flags a student.
 	MissingSubmissionsThreshold = 3
-	MaxRetries                 = 3
 )
 
+// ErrFlagClosed is returned when acknowledging or resolving a flag that is already resolved or auto-cleared.
+var ErrFlagClosed = errors.New("risk flag is not open")
+
 type Service struct {
-	pool   *pgxpool.Pool
 	riskRepo *storage.RiskRepo
+	rules    *Registry
 }
 
-func NewService(pool *pgxpool.Pool, riskRepo *storage.RiskRepo) *Service {
-	return &Service{pool: pool, riskRepo: riskRepo}
+func NewService(riskRepo *storage.RiskRepo, rules *Registry) *Service {
+	return &Service{riskRepo: riskRepo, rules: rules}
 }
 
-// RecomputeForClass clears existing flags and recomputes at-risk students for the class.
-func (s *Service) RecomputeForClass(ctx context.Context, classID string) error {
-	if err := s.riskRepo.DeleteRiskFlagsForClass(ctx, classID); err != nil {
+// RecomputeForClass runs the rules enabled for the class, with its thresholds, over a snapshot of the class taken
+// inside tx, and reconciles the class's stored flags with the result: new flags open, flags that still hold keep
+// their status, and open or acknowledged flags that no longer hold (including those of disabled rules) auto-clear.
+func (s *Service) RecomputeForClass(ctx context.Context, tx pgx.Tx, classID string) error {
+	repo := s.riskRepo.WithTx(tx)
+	districtID, settings, err := repo.RiskSettingsFor(ctx, classID)
+	if err != nil {
 		return err
 	}
-
-	// Missing submissions: students with assigned but no graded submission count above threshold
-	rows, err := s.pool.Query(ctx, `
-		WITH assigned AS (
-			SELECT DISTINCT (payload->>'student_id') AS student_id
-			FROM events
-			WHERE type = 'ASSIGNMENT_ASSIGNED' AND payload->>'class_id' = $1
-		),
-		graded AS (
-			SELECT DISTINCT (payload->>'student_id') AS student_id
-			FROM events
-			WHERE type = 'SUBMISSION_GRADED' AND payload->>'class_id' = $1
-		),
-		missing AS (
-			SELECT a.student_id FROM assigned a
-			LEFT JOIN graded g ON a.student_id = g.student_id
-			WHERE g.student_id IS NULL
-		)
-		SELECT student_id FROM missing
-	`, classID)
+	cfg := resolveConfig(settings, classID, districtID)
+	snap, err := loadSnapshot(ctx, repo, classID, cfg.Thresholds)
+	if err != nil {
+		return err
+	}
+	flags, err := evaluate(snap, s.rules.Enabled(cfg.EnabledRules), cfg.Thresholds)
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
-	for rows.Next() {
-		var studentID string
-		if err := rows.Scan(&studentID); err != nil {
+	stored, err := repo.RiskFlagsForClass(ctx, classID)
+	if err != nil {
+		return err
+	}
+	for _, u := range reconcile(stored, flags, time.Now()) {
+		if err := repo.SaveRiskFlag(ctx, u.flag, u.changed); err != nil {
 			return err
 		}
-		_ = s.riskRepo.UpsertRiskFlag(ctx, studentID, classID, domain.RiskReasonMissingSubmissions)
 	}
-	if err := rows.Err(); err != nil {
-		return err
+	return nil
+}
+
+func loadSnapshot(ctx context.Context, repo *storage.RiskRepo, classID string, t Thresholds) (*ClassSnapshot, error) {
+	snap := &ClassSnapshot{ClassID: classID}
+	var err error
+	if snap.Missing, err = repo.MissingCounts(ctx, classID); err != nil {
+		return nil, err
+	}
+	if snap.Completion, err = repo.CompletionRates(ctx, classID); err != nil {
+		return nil, err
 	}
+	if snap.Scores, err = repo.RecentScores(ctx, classID, t.TrendWindow); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
 
-	// Completion below median: get class completion rate per student, then median, then flag below
-	var medianRate sql.NullFloat64
-	err = s.pool.QueryRow(ctx, `
-		WITH student_rates AS (
-			SELECT
-				payload->>'student_id' AS student_id,
-				COUNT(DISTINCT CASE WHEN type = 'SUBMISSION_GRADED' THEN payload->>'assignment_id' END)::float / NULLIF(COUNT(DISTINCT CASE WHEN type = 'ASSIGNMENT_ASSIGNED' THEN payload->>'assignment_id' END), 0) AS rate
-			FROM events
-			WHERE payload->>'class_id' = $1
-			GROUP BY payload->>'student_id'
-		),
-		ordered AS (
-			SELECT rate, row_number() OVER (ORDER BY rate) AS rn, count(*) OVER () AS cnt
-			FROM student_rates WHERE rate IS NOT NULL
-		)
-		SELECT AVG(rate) FROM ordered WHERE rn IN (cnt/2, cnt/2 + 1)
-	`, classID).Scan(&medianRate)
-	if err == nil && medianRate.Valid {
-		rows2, err := s.pool.Query(ctx, `
-			WITH student_rates AS (
-				SELECT
-					payload->>'student_id' AS student_id,
-					COUNT(DISTINCT CASE WHEN type = 'SUBMISSION_GRADED' THEN payload->>'assignment_id' END)::float / NULLIF(COUNT(DISTINCT CASE WHEN type = 'ASSIGNMENT_ASSIGNED' THEN payload->>'assignment_id' END), 0) AS rate
-				FROM events WHERE payload->>'class_id' = $1
-				GROUP BY payload->>'student_id'
-			)
-			SELECT student_id FROM student_rates WHERE rate IS NOT NULL AND rate < $2
-		`, classID, medianRate.Float64)
-		if err == nil {
-			for rows2.Next() {
-				var studentID string
-				if err := rows2.Scan(&studentID); err != nil {
-					rows2.Close()
-					break
+// evaluate runs rules over the snapshot and turns their findings into flags.
+func evaluate(snap *ClassSnapshot, rules []RiskRule, t Thresholds) ([]domain.RiskFlag, error) {
+	var flags []domain.RiskFlag
+	for _, rule := range rules {
+		for _, f := range rule.Evaluate(snap, t) {
+			flag := domain.RiskFlag{StudentID: f.StudentID, ClassID: snap.ClassID, Reason: rule.Name()}
+			if f.Evidence != nil {
+				ev, err := json.Marshal(f.Evidence)
+				if err != nil {
+					return nil, fmt.Errorf("rule %s evidence: %w", rule.Name(), err)
 				}
-				_ = s.riskRepo.UpsertRiskFlag(ctx, studentID, classID, domain.RiskReasonBelowMedian)
+				flag.Evidence = ev
 			}
-			rows2.Close()
+			flags = append(flags, flag)
 		}
 	}
+	return flags, nil
+}
+
+// Rules lists the registered rule names.
+func (s *Service) Rules() []string {
+	return s.rules.Names()
+}
+
+func (s *Service) ListSettings(ctx context.Context) ([]domain.RiskSetting, error) {
+	return s.riskRepo.ListRiskSettings(ctx)
+}
+
+// SaveSetting validates the thresholds and rule names before storing them. They apply from the class's next recompute.
+func (s *Service) SaveSetting(ctx context.Context, scope, scopeID string, cfg Config) error {
+	if scope != domain.RiskScopeClass && scope != domain.RiskScopeDistrict {
+		return fmt.Errorf("unknown scope %q", scope)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := s.rules.Check(cfg.EnabledRules); err != nil {
+		return err
+	}
+	return s.riskRepo.UpsertRiskSetting(ctx, domain.RiskSetting{
+		Scope: scope, ScopeID: scopeID, MissingCount: cfg.MissingCount, TrendWindow: cfg.TrendWindow,
+		MedianMargin: cfg.MedianMargin, EnabledRules: cfg.EnabledRules,
+	})
+}
+
+// DeleteSetting reports false if there was no setting for the scope.
+func (s *Service) DeleteSetting(ctx context.Context, scope, scopeID string) (bool, error) {
+	return s.riskRepo.DeleteRiskSetting(ctx, scope, scopeID)
+}
+
+// Acknowledge marks the student's open flag for reason as seen by teacherID, with an optional note. Acknowledging
+// again updates the note. It returns nil if there is no such flag.
+func (s *Service) Acknowledge(ctx context.Context, classID, studentID, reason, teacherID, note string) (*domain.RiskFlag, error) {
+	return s.setStatus(ctx, classID, studentID, reason, domain.RiskFlagAcknowledged, teacherID, note)
+}
 
-	// Score trend downward: last 2 graded scores - if latest < previous, flag
-	rows3, err := s.pool.Query(ctx, `
-		WITH graded AS (
-			SELECT payload->>'student_id' AS student_id, (payload->>'score')::float AS score,
-			       row_number() OVER (PARTITION BY payload->>'student_id' ORDER BY created_at DESC) AS rn
-			FROM events
-			WHERE type = 'SUBMISSION_GRADED' AND payload->>'class_id' = $1
-		),
-		pair AS (
-			SELECT student_id, MAX(CASE WHEN rn = 1 THEN score END) AS last_score,
-			       MAX(CASE WHEN rn = 2 THEN score END) AS prev_score
-			FROM graded GROUP BY student_id
-		)
-		SELECT student_id FROM pair WHERE prev_score IS NOT NULL AND last_score < prev_score
-	`, classID)
+// Resolve closes the student's flag for reason on behalf of teacherID. It stays resolved while the rule keeps
+// firing and is reopened only if the rule fires again after it stopped.
+func (s *Service) Resolve(ctx context.Context, classID, studentID, reason, teacherID, note string) (*domain.RiskFlag, error) {
+	return s.setStatus(ctx, classID, studentID, reason, domain.RiskFlagResolved, teacherID, note)
+}
+
+func (s *Service) setStatus(ctx context.Context, classID, studentID, reason, status, teacherID, note string) (*domain.RiskFlag, error) {
+	ok, err := s.riskRepo.SetRiskFlagStatus(ctx, classID, studentID, reason, status, teacherID, note)
 	if err != nil {
-		return nil
+		return nil, err
 	}
-	defer rows3.Close()
-	for rows3.Next() {
-		var studentID string
-		if err := rows3.Scan(&studentID); err != nil {
-			break
-		}
-		_ = s.riskRepo.UpsertRiskFlag(ctx, studentID, classID, domain.RiskReasonScoreTrendDown)
+	f, err := s.riskRepo.GetRiskFlag(ctx, classID, studentID, reason)
+	if err != nil || f == nil {
+		return nil, err
 	}
+	if !ok {
+		return f, ErrFlagClosed
+	}
+	return f, nil
+}
 
-	return nil
+// History returns the student's flags in the class in every status, with their status changes.
+func (s *Service) History(ctx context.Context, classID, studentID string) (*domain.StudentRiskHistory, error) {
+	flags, err := s.riskRepo.RiskFlagsForStudent(ctx, classID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.riskRepo.RiskFlagHistory(ctx, classID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.StudentRiskHistory{StudentID: studentID, ClassID: classID, Flags: flags, History: changes}, nil
 }
@@ -0,0 +1,61 @@
+package risk
+
+import (
+	"time"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+// flagUpdate is a flag to save after a recompute; changed marks a status change, which goes into the flag's history.
+type flagUpdate struct {
+	flag    domain.RiskFlag
+	changed bool
+}
+
+type flagKey struct{ studentID, reason string }
+
+// reconcile merges the flags the rules raised on this recompute into the class's stored flags:
+//   - a new flag, or one raised again after it was resolved or auto-cleared, is (re)opened from now;
+//   - a flag that is still active keeps its status, acknowledgement and first_flagged_at, and takes the new evidence;
+//   - an open or acknowledged flag whose rule no longer fires is auto-cleared; a resolved one just becomes inactive.
+//
+// Stored flags that were already inactive and did not fire again are left alone.
+func reconcile(stored, raised []domain.RiskFlag, now time.Time) []flagUpdate {
+	byKey := make(map[flagKey]domain.RiskFlag, len(stored))
+	for _, f := range stored {
+		byKey[flagKey{f.StudentID, f.Reason}] = f
+	}
+
+	var out []flagUpdate
+	seen := make(map[flagKey]bool, len(raised))
+	for _, f := range raised {
+		k := flagKey{f.StudentID, f.Reason}
+		seen[k] = true
+		prev, ok := byKey[k]
+		if ok && prev.Active {
+			prev.Evidence = f.Evidence
+			out = append(out, flagUpdate{flag: prev})
+			continue
+		}
+		f.Status = domain.RiskFlagOpen
+		f.Active = true
+		f.FirstFlaggedAt = now
+		f.AcknowledgedAt, f.AcknowledgedBy, f.Note, f.ResolvedAt = nil, "", "", nil
+		out = append(out, flagUpdate{flag: f, changed: true})
+	}
+
+	for _, f := range stored {
+		if seen[flagKey{f.StudentID, f.Reason}] || !f.Active {
+			continue
+		}
+		f.Active = false
+		if f.Status == domain.RiskFlagResolved {
+			out = append(out, flagUpdate{flag: f})
+			continue
+		}
+		f.Status = domain.RiskFlagAutoCleared
+		f.ResolvedAt = &now
+		out = append(out, flagUpdate{flag: f, changed: true})
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package risk
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-48 * time.Hour)
+	ackAt := now.Add(-24 * time.Hour)
+	stored := func(student, status string, active bool) domain.RiskFlag {
+		f := domain.RiskFlag{StudentID: student, ClassID: "c1", Reason: "missing_submissions", Status: status, Active: active, FirstFlaggedAt: earlier}
+		if status == domain.RiskFlagAcknowledged {
+			f.AcknowledgedAt, f.AcknowledgedBy, f.Note = &ackAt, "t1", "called home"
+		}
+		if status == domain.RiskFlagResolved || status == domain.RiskFlagAutoCleared {
+			f.ResolvedAt = &ackAt
+		}
+		return f
+	}
+	raised := func(student string) domain.RiskFlag {
+		return domain.RiskFlag{StudentID: student, ClassID: "c1", Reason: "missing_submissions", Evidence: json.RawMessage(`{"missing":4}`)}
+	}
+
+	tests := []struct {
+		name        string
+		stored      []domain.RiskFlag
+		raised      []domain.RiskFlag
+		wantStatus  string
+		wantActive  bool
+		wantChanged bool
+		wantFirst   time.Time
+		wantAcked   bool
+		wantNone    bool
+	}{
+		{name: "new flag opens", raised: []domain.RiskFlag{raised("s1")},
+			wantStatus: domain.RiskFlagOpen, wantActive: true, wantChanged: true, wantFirst: now},
+		{name: "open flag still firing", stored: []domain.RiskFlag{stored("s1", domain.RiskFlagOpen, true)}, raised: []domain.RiskFlag{raised("s1")},
+			wantStatus: domain.RiskFlagOpen, wantActive: true, wantFirst: earlier},
+		{name: "acknowledged flag still firing keeps acknowledgement", stored: []domain.RiskFlag{stored("s1", domain.RiskFlagAcknowledged, true)}, raised: []domain.RiskFlag{raised("s1")},
+			wantStatus: domain.RiskFlagAcknowledged, wantActive: true, wantFirst: earlier, wantAcked: true},
+		{name: "resolved flag still firing stays resolved", stored: []domain.RiskFlag{stored("s1", domain.RiskFlagResolved, true)}, raised: []domain.RiskFlag{raised("s1")},
+			wantStatus: domain.RiskFlagResolved, wantActive: true, wantFirst: earlier},
+		{name: "open flag clears", stored: []domain.RiskFlag{stored("s1", domain.RiskFlagOpen, true)},
+			wantStatus: domain.RiskFlagAutoCleared, wantChanged: true, wantFirst: earlier},
+		{name: "acknowledged flag clears", stored: []domain.RiskFlag{stored("s1", domain.RiskFlagAcknowledged, true)},
+			wantStatus: domain.RiskFlagAutoCleared, wantChanged: true, wantFirst: earlier, wantAcked: true},
+		{name: "resolved flag stops firing", stored: []domain.RiskFlag{stored("s1", domain.RiskFlagResolved, true)},
+			wantStatus: domain.RiskFlagResolved, wantFirst: earlier},
+		{name: "auto-cleared flag reopens", stored: []domain.RiskFlag{stored("s1", domain.RiskFlagAutoCleared, false)}, raised: []domain.RiskFlag{raised("s1")},
+			wantStatus: domain.RiskFlagOpen, wantActive: true, wantChanged: true, wantFirst: now},
+		{name: "resolved inactive flag reopens", stored: []domain.RiskFlag{stored("s1", domain.RiskFlagResolved, false)}, raised: []domain.RiskFlag{raised("s1")},
+			wantStatus: domain.RiskFlagOpen, wantActive: true, wantChanged: true, wantFirst: now},
+		{name: "inactive flag left alone", stored: []domain.RiskFlag{stored("s1", domain.RiskFlagAutoCleared, false)}, wantNone: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reconcile(tt.stored, tt.raised, now)
+			if tt.wantNone {
+				if len(got) != 0 {
+					t.Fatalf("reconcile() = %+v, want no updates", got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("reconcile() = %+v, want one update", got)
+			}
+			u := got[0]
+			if u.flag.Status != tt.wantStatus || u.flag.Active != tt.wantActive || u.changed != tt.wantChanged {
+				t.Errorf("status %s active %v changed %v, want %s %v %v",
+					u.flag.Status, u.flag.Active, u.changed, tt.wantStatus, tt.wantActive, tt.wantChanged)
+			}
+			if !u.flag.FirstFlaggedAt.Equal(tt.wantFirst) {
+				t.Errorf("first_flagged_at = %v, want %v", u.flag.FirstFlaggedAt, tt.wantFirst)
+			}
+			if acked := u.flag.AcknowledgedAt != nil; acked != tt.wantAcked {
+				t.Errorf("acknowledged = %v, want %v", acked, tt.wantAcked)
+			}
+			if tt.wantStatus == domain.RiskFlagOpen && u.flag.ResolvedAt != nil {
+				t.Errorf("open flag has resolved_at %v", u.flag.ResolvedAt)
+			}
+			if tt.wantStatus == domain.RiskFlagAutoCleared && (u.flag.ResolvedAt == nil || !u.flag.ResolvedAt.Equal(now)) {
+				t.Errorf("auto-cleared resolved_at = %v, want %v", u.flag.ResolvedAt, now)
+			}
+			if tt.wantActive && string(u.flag.Evidence) != `{"missing":4}` {
+				t.Errorf("evidence = %s, want the new evidence", u.flag.Evidence)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package risk
+
+import (
+	"fmt"
+)
+
+// RiskRule is one at-risk rule. Rules are pure: they see only the class snapshot and the class's thresholds,
+// so each can be tested against in-memory data.
+type RiskRule interface {
+	// Name is the rule's reason code, stored on its flags (e.g. missing_submissions).
+	Name() string
+	// Evaluate returns the students the rule flags in the class, each with the evidence behind it.
+	Evaluate(s *ClassSnapshot, t Thresholds) []Finding
+}
+
+// Finding is one student flagged by a rule. Evidence is marshaled to JSON and stored with the flag.
+type Finding struct {
+	StudentID string
+	Evidence  interface{}
+}
+
+// ClassSnapshot is the per-student data the rules evaluate for one class, keyed by student.
+type ClassSnapshot struct {
+	ClassID string
+	// Missing counts assignments past due without a submission, for students with any.
+	Missing map[string]int
+	// Completion is the completion rate, for students with assignments.
+	Completion map[string]float64
+	// Scores are the latest graded scores (up to the trend window), newest first.
+	Scores map[string][]float64
+}
+
+// Registry holds the available rules in registration order, which is the order they run in.
+type Registry struct {
+	rules []RiskRule
+	names map[string]bool
+}
+
+// NewRegistry registers rules; it panics on a duplicate name, as that is a programming error.
+func NewRegistry(rules ...RiskRule) *Registry {
+	r := &Registry{names: make(map[string]bool)}
+	for _, rule := range rules {
+		if err := r.Register(rule); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// DefaultRegistry has the built-in rules.
+func DefaultRegistry() *Registry {
+	return NewRegistry(MissingSubmissions{}, BelowMedianCompletion{}, ScoreTrendDown{})
+}
+
+func (r *Registry) Register(rule RiskRule) error {
+	if r.names[rule.Name()] {
+		return fmt.Errorf("risk rule %q already registered", rule.Name())
+	}
+	r.rules = append(r.rules, rule)
+	r.names[rule.Name()] = true
+	return nil
+}
+
+// Names lists the registered rules.
+func (r *Registry) Names() []string {
+	out := make([]string, len(r.rules))
+	for i, rule := range r.rules {
+		out[i] = rule.Name()
+	}
+	return out
+}
+
+// Enabled returns the registered rules named in enabled, or every rule if enabled is nil.
+func (r *Registry) Enabled(enabled []string) []RiskRule {
+	if enabled == nil {
+		return r.rules
+	}
+	var out []RiskRule
+	for _, rule := range r.rules {
+		for _, name := range enabled {
+			if rule.Name() == name {
+				out = append(out, rule)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Check reports an error for any name that is not a registered rule.
+func (r *Registry) Check(names []string) error {
+	for _, name := range names {
+		if !r.names[name] {
+			return fmt.Errorf("unknown risk rule %q", name)
+		}
+	}
+	return nil
+}
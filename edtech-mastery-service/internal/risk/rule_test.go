@@ -0,0 +1,83 @@
+package risk
+
+import (
+	"reflect"
+	"testing"
+)
+
+// stubRule flags fixed students.
+type stubRule struct {
+	name     string
+	students []string
+}
+
+func (r stubRule) Name() string { return r.name }
+
+func (r stubRule) Evaluate(*ClassSnapshot, Thresholds) []Finding {
+	var out []Finding
+	for _, s := range r.students {
+		out = append(out, Finding{StudentID: s})
+	}
+	return out
+}
+
+func TestRegistry(t *testing.T) {
+	reg := DefaultRegistry()
+	if err := reg.Register(stubRule{name: "custom"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register(MissingSubmissions{}); err == nil {
+		t.Error("registering a duplicate name succeeded")
+	}
+	wantAll := []string{"missing_submissions", "completion_below_median", "score_trend_down", "custom"}
+	if got := reg.Names(); !reflect.DeepEqual(got, wantAll) {
+		t.Errorf("Names() = %v, want %v", got, wantAll)
+	}
+
+	tests := []struct {
+		name    string
+		enabled []string
+		want    []string
+	}{
+		{name: "all by default", enabled: nil, want: wantAll},
+		{name: "registry order", enabled: []string{"custom", "missing_submissions"}, want: []string{"missing_submissions", "custom"}},
+		{name: "none", enabled: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range reg.Enabled(tt.enabled) {
+				got = append(got, r.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := reg.Check([]string{"custom", "score_trend_down"}); err != nil {
+		t.Errorf("Check() = %v", err)
+	}
+	if err := reg.Check([]string{"unknown"}); err == nil {
+		t.Error("Check() accepted an unknown rule")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	snap := &ClassSnapshot{ClassID: "c1", Missing: map[string]int{"s1": 4}}
+	rules := []RiskRule{MissingSubmissions{}, stubRule{name: "custom", students: []string{"s2"}}}
+	flags, err := evaluate(snap, rules, DefaultThresholds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flags) != 2 {
+		t.Fatalf("flags = %+v, want 2", flags)
+	}
+	if f := flags[0]; f.StudentID != "s1" || f.ClassID != "c1" || f.Reason != "missing_submissions" ||
+		string(f.Evidence) != `{"missing":4,"threshold":3}` {
+		t.Errorf("flags[0] = %+v evidence %s", f, f.Evidence)
+	}
+	if f := flags[1]; f.StudentID != "s2" || f.Reason != "custom" || f.Evidence != nil {
+		t.Errorf("flags[1] = %+v", f)
+	}
+}
@@ -0,0 +1,107 @@
+package risk
+
+import (
+	"sort"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+// MissingSubmissions flags students with at least Thresholds.MissingCount missing assignments.
+type MissingSubmissions struct{}
+
+type MissingEvidence struct {
+	Missing   int `json:"missing"`
+	Threshold int `json:"threshold"`
+}
+
+func (MissingSubmissions) Name() string { return domain.RiskReasonMissingSubmissions }
+
+func (MissingSubmissions) Evaluate(s *ClassSnapshot, t Thresholds) []Finding {
+	var out []Finding
+	for studentID, n := range s.Missing {
+		if n >= t.MissingCount {
+			out = append(out, Finding{StudentID: studentID, Evidence: MissingEvidence{Missing: n, Threshold: t.MissingCount}})
+		}
+	}
+	return sorted(out)
+}
+
+// BelowMedianCompletion flags students whose completion rate is more than Thresholds.MedianMargin below the
+// class median.
+type BelowMedianCompletion struct{}
+
+type CompletionEvidence struct {
+	Completion float64 `json:"completion"`
+	Median     float64 `json:"median"`
+	Margin     float64 `json:"margin"`
+}
+
+func (BelowMedianCompletion) Name() string { return domain.RiskReasonBelowMedian }
+
+func (BelowMedianCompletion) Evaluate(s *ClassSnapshot, t Thresholds) []Finding {
+	if len(s.Completion) == 0 {
+		return nil
+	}
+	rates := make([]float64, 0, len(s.Completion))
+	for _, r := range s.Completion {
+		rates = append(rates, r)
+	}
+	m := median(rates)
+	var out []Finding
+	for studentID, r := range s.Completion {
+		if r < m-t.MedianMargin {
+			out = append(out, Finding{StudentID: studentID, Evidence: CompletionEvidence{Completion: r, Median: m, Margin: t.MedianMargin}})
+		}
+	}
+	return sorted(out)
+}
+
+// ScoreTrendDown flags students whose latest score is below the mean of their previous ones within
+// Thresholds.TrendWindow. It needs at least two scores; fewer than the window are compared as they are.
+type ScoreTrendDown struct{}
+
+// TrendEvidence.Window is the number of scores compared, the latest included; it is below
+// Thresholds.TrendWindow when the student has fewer scores.
+type TrendEvidence struct {
+	Latest       float64 `json:"latest"`
+	PreviousMean float64 `json:"previous_mean"`
+	Window       int     `json:"window"`
+}
+
+func (ScoreTrendDown) Name() string { return domain.RiskReasonScoreTrendDown }
+
+func (ScoreTrendDown) Evaluate(s *ClassSnapshot, t Thresholds) []Finding {
+	var out []Finding
+	for studentID, scores := range s.Scores {
+		if len(scores) > t.TrendWindow {
+			scores = scores[:t.TrendWindow]
+		}
+		if len(scores) < 2 {
+			continue
+		}
+		var sum float64
+		for _, sc := range scores[1:] {
+			sum += sc
+		}
+		if prev := sum / float64(len(scores)-1); scores[0] < prev {
+			out = append(out, Finding{StudentID: studentID, Evidence: TrendEvidence{Latest: scores[0], PreviousMean: prev, Window: len(scores)}})
+		}
+	}
+	return sorted(out)
+}
+
+// sorted orders findings by student, since rules range over maps.
+func sorted(fs []Finding) []Finding {
+	sort.Slice(fs, func(i, j int) bool { return fs[i].StudentID < fs[j].StudentID })
+	return fs
+}
+
+// median sorts xs in place. xs must not be empty.
+func median(xs []float64) float64 {
+	sort.Float64s(xs)
+	n := len(xs)
+	if n%2 == 1 {
+		return xs[n/2]
+	}
+	return (xs[n/2-1] + xs[n/2]) / 2
+}
@@ -0,0 +1,117 @@
+package risk
+
+import (
+	"reflect"
+	"testing"
+)
+
+// studentsOf lists the flagged students, to compare rules' findings without their evidence.
+func studentsOf(fs []Finding) []string {
+	var out []string
+	for _, f := range fs {
+		out = append(out, f.StudentID)
+	}
+	return out
+}
+
+func TestMissingSubmissions(t *testing.T) {
+	snap := &ClassSnapshot{Missing: map[string]int{"s1": 1, "s2": 2, "s3": 3, "s4": 5}}
+	tests := []struct {
+		name      string
+		threshold int
+		want      []string
+	}{
+		{name: "default threshold", threshold: MissingSubmissionsThreshold, want: []string{"s3", "s4"}},
+		{name: "any missing", threshold: 1, want: []string{"s1", "s2", "s3", "s4"}},
+		{name: "above everyone", threshold: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := DefaultThresholds
+			th.MissingCount = tt.threshold
+			got := MissingSubmissions{}.Evaluate(snap, th)
+			if !reflect.DeepEqual(studentsOf(got), tt.want) {
+				t.Errorf("Evaluate() = %v, want %v", studentsOf(got), tt.want)
+			}
+		})
+	}
+
+	got := MissingSubmissions{}.Evaluate(snap, DefaultThresholds)
+	if want := (MissingEvidence{Missing: 5, Threshold: 3}); got[1].Evidence != want {
+		t.Errorf("evidence = %+v, want %+v", got[1].Evidence, want)
+	}
+}
+
+func TestBelowMedianCompletion(t *testing.T) {
+	tests := []struct {
+		name       string
+		completion map[string]float64
+		margin     float64
+		want       []string
+	}{
+		{name: "no students"},
+		{name: "single student", completion: map[string]float64{"s1": 0.2}},
+		{name: "odd count", completion: map[string]float64{"s1": 0.2, "s2": 0.5, "s3": 0.9}, want: []string{"s1"}},
+		{name: "even count", completion: map[string]float64{"s1": 0.2, "s2": 0.4, "s3": 0.6, "s4": 1}, want: []string{"s1", "s2"}},
+		{name: "all equal", completion: map[string]float64{"s1": 0.5, "s2": 0.5}},
+		{name: "within margin", completion: map[string]float64{"s1": 0.4, "s2": 0.5, "s3": 0.9}, margin: 0.2},
+		{name: "beyond margin", completion: map[string]float64{"s1": 0.2, "s2": 0.4, "s3": 0.5, "s4": 0.6, "s5": 0.9}, margin: 0.2, want: []string{"s1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := DefaultThresholds
+			th.MedianMargin = tt.margin
+			got := BelowMedianCompletion{}.Evaluate(&ClassSnapshot{Completion: tt.completion}, th)
+			if !reflect.DeepEqual(studentsOf(got), tt.want) {
+				t.Errorf("Evaluate() = %v, want %v", studentsOf(got), tt.want)
+			}
+		})
+	}
+
+	got := BelowMedianCompletion{}.Evaluate(&ClassSnapshot{Completion: map[string]float64{"s1": 0.2, "s2": 0.5, "s3": 0.9}}, DefaultThresholds)
+	if want := (CompletionEvidence{Completion: 0.2, Median: 0.5}); got[0].Evidence != want {
+		t.Errorf("evidence = %+v, want %+v", got[0].Evidence, want)
+	}
+}
+
+func TestScoreTrendDown(t *testing.T) {
+	snap := &ClassSnapshot{Scores: map[string][]float64{
+		"dropped":    {0.6, 0.8},
+		"improved":   {0.9, 0.7},
+		"flat":       {0.7, 0.7},
+		"one score":  {0.3},
+		"dip":        {0.7, 0.8, 0.5, 0.4},
+		"long slide": {0.5, 0.6, 0.7, 0.8},
+	}}
+	tests := []struct {
+		name   string
+		window int
+		want   []string
+	}{
+		{name: "latest against previous", window: 2, want: []string{"dip", "dropped", "long slide"}},
+		{name: "latest against mean of three", window: 4, want: []string{"dropped", "long slide"}},
+		{name: "window longer than history", window: 10, want: []string{"dropped", "long slide"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := DefaultThresholds
+			th.TrendWindow = tt.window
+			got := ScoreTrendDown{}.Evaluate(snap, th)
+			if !reflect.DeepEqual(studentsOf(got), tt.want) {
+				t.Errorf("Evaluate() = %v, want %v", studentsOf(got), tt.want)
+			}
+		})
+	}
+
+	got := ScoreTrendDown{}.Evaluate(&ClassSnapshot{Scores: map[string][]float64{"s1": {0.5, 0.75}}}, DefaultThresholds)
+	if want := (TrendEvidence{Latest: 0.5, PreviousMean: 0.75, Window: 2}); len(got) != 1 || got[0].Evidence != want {
+		t.Errorf("findings = %+v, want evidence %+v", got, want)
+	}
+
+	th := DefaultThresholds
+	th.TrendWindow = 5
+	got = ScoreTrendDown{}.Evaluate(&ClassSnapshot{Scores: map[string][]float64{"s1": {0.4, 0.6, 0.8}}}, th)
+	if want := (TrendEvidence{Latest: 0.4, PreviousMean: 0.7, Window: 3}); len(got) != 1 || got[0].Evidence != want {
+		t.Errorf("short history: findings = %+v, want evidence %+v", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package risk
+
+import (
+	"fmt"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+// Thresholds tune the at-risk rules.
+type Thresholds struct {
+	// MissingCount is how many missing assignments flag missing_submissions.
+	MissingCount int `json:"missing_count"`
+	// TrendWindow is how many recent graded scores score_trend_down looks at: the latest is compared with the mean
+	// of the others.
+	TrendWindow int `json:"trend_window"`
+	// MedianMargin is how far (as a rate, 0..1) below the class median a student's completion must be to flag
+	// completion_below_median.
+	MedianMargin float64 `json:"median_margin"`
+}
+
+// DefaultThresholds apply when neither the class nor its district has risk settings.
+var DefaultThresholds = Thresholds{MissingCount: MissingSubmissionsThreshold, TrendWindow: 2, MedianMargin: 0}
+
+func (t Thresholds) Validate() error {
+	switch {
+	case t.MissingCount < 1:
+		return fmt.Errorf("missing_count must be at least 1")
+	case t.TrendWindow < 2 || t.TrendWindow > 20:
+		return fmt.Errorf("trend_window must be between 2 and 20")
+	case t.MedianMargin < 0 || t.MedianMargin >= 1:
+		return fmt.Errorf("median_margin must satisfy 0 <= median_margin < 1")
+	}
+	return nil
+}
+
+// Config is what a class runs with: its thresholds and the rules enabled for it (nil for all, empty for none).
+type Config struct {
+	Thresholds
+	EnabledRules []string `json:"enabled_rules"`
+}
+
+// DefaultConfig runs every rule with DefaultThresholds.
+var DefaultConfig = Config{Thresholds: DefaultThresholds}
+
+func configFromSetting(s domain.RiskSetting) Config {
+	return Config{
+		Thresholds:   Thresholds{MissingCount: s.MissingCount, TrendWindow: s.TrendWindow, MedianMargin: s.MedianMargin},
+		EnabledRules: s.EnabledRules,
+	}
+}
+
+// resolveConfig picks the class setting, then the district setting, then DefaultConfig.
+func resolveConfig(settings []domain.RiskSetting, classID, districtID string) Config {
+	var district *domain.RiskSetting
+	for i := range settings {
+		st := &settings[i]
+		switch {
+		case st.Scope == domain.RiskScopeClass && st.ScopeID == classID:
+			return configFromSetting(*st)
+		case st.Scope == domain.RiskScopeDistrict && districtID != "" && st.ScopeID == districtID:
+			district = st
+		}
+	}
+	if district != nil {
+		return configFromSetting(*district)
+	}
+	return DefaultConfig
+}
@@ -0,0 +1,86 @@
+package risk
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func TestResolveConfig(t *testing.T) {
+	settings := []domain.RiskSetting{
+		{Scope: domain.RiskScopeDistrict, ScopeID: "d1", MissingCount: 2, TrendWindow: 3, MedianMargin: 0.1},
+		{Scope: domain.RiskScopeClass, ScopeID: "c1", MissingCount: 1, TrendWindow: 2, MedianMargin: 0},
+		{Scope: domain.RiskScopeClass, ScopeID: "c2", MissingCount: 4, TrendWindow: 5, MedianMargin: 0.2, EnabledRules: []string{"score_trend_down"}},
+	}
+	tests := []struct {
+		name       string
+		classID    string
+		districtID string
+		want       Config
+	}{
+		{name: "class setting wins", classID: "c1", districtID: "d1", want: Config{Thresholds: Thresholds{MissingCount: 1, TrendWindow: 2}}},
+		{name: "district setting", classID: "c3", districtID: "d1", want: Config{Thresholds: Thresholds{MissingCount: 2, TrendWindow: 3, MedianMargin: 0.1}}},
+		{name: "class setting without district", classID: "c2", want: Config{Thresholds: Thresholds{MissingCount: 4, TrendWindow: 5, MedianMargin: 0.2}, EnabledRules: []string{"score_trend_down"}}},
+		{name: "defaults", classID: "c3", districtID: "d2", want: DefaultConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveConfig(settings, tt.classID, tt.districtID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThresholdsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		t       Thresholds
+		wantErr bool
+	}{
+		{name: "defaults", t: DefaultThresholds},
+		{name: "zero missing count", t: Thresholds{MissingCount: 0, TrendWindow: 2}, wantErr: true},
+		{name: "window of one", t: Thresholds{MissingCount: 1, TrendWindow: 1}, wantErr: true},
+		{name: "window too long", t: Thresholds{MissingCount: 1, TrendWindow: 21}, wantErr: true},
+		{name: "negative margin", t: Thresholds{MissingCount: 1, TrendWindow: 2, MedianMargin: -0.1}, wantErr: true},
+		{name: "margin of one", t: Thresholds{MissingCount: 1, TrendWindow: 2, MedianMargin: 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.t.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigJSONKeepsNoRulesApart(t *testing.T) {
+	// null runs every rule and [] runs none, so both must survive a round trip through the admin API.
+	for _, tt := range []struct {
+		rules []string
+		want  string
+	}{
+		{rules: nil, want: `"enabled_rules":null`},
+		{rules: []string{}, want: `"enabled_rules":[]`},
+	} {
+		cfg := DefaultConfig
+		cfg.EnabledRules = tt.rules
+		b, err := json.Marshal(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), tt.want) {
+			t.Errorf("json = %s, want %s", b, tt.want)
+		}
+		var back Config
+		if err := json.Unmarshal(b, &back); err != nil {
+			t.Fatal(err)
+		}
+		if (back.EnabledRules == nil) != (tt.rules == nil) {
+			t.Errorf("round trip of %#v gave %#v", tt.rules, back.EnabledRules)
+		}
+	}
+}
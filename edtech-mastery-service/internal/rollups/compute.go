@@ -3,14 +3,17 @@ package rollups
 import (
 	"context"
 	"math"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
 	"github.com/edtech-mastery/student-progress-service/internal/storage"
 )
 
 type Service struct {
-	pool   *pgxpool.Pool
+	pool    *pgxpool.Pool
 	rollups *storage.RollupsRepo
 }
 
@@ -18,32 +21,230 @@ func NewService(pool *pgxpool.Pool, rollups *storage.RollupsRepo) *Service {
 	return &Service{pool: pool, rollups: rollups}
 }
 
-func (s *Service) RecomputeForClass(ctx context.Context, classID string) error {
-	var completionRate float64
-	var avgScore *float64
-	err := s.pool.QueryRow(ctx, `
-		WITH assigned AS (
-			SELECT COUNT(DISTINCT (payload->>'student_id') || '|' || (payload->>'assignment_id')) AS cnt
-			FROM events
-			WHERE type = 'ASSIGNMENT_ASSIGNED' AND payload->>'class_id' = $1
-		),
-		graded AS (
-			SELECT COUNT(DISTINCT (payload->>'student_id') || '|' || (payload->>'assignment_id')) AS cnt
-			FROM events
-			WHERE type = 'SUBMISSION_GRADED' AND payload->>'class_id' = $1
-		),
-		scores AS (
-			SELECT AVG((payload->>'score')::float) AS avg_s
-			FROM events
-			WHERE type = 'SUBMISSION_GRADED' AND payload->>'class_id' = $1
-		)
-		SELECT COALESCE(g.cnt::float / NULLIF(a.cnt, 0), 0), s.avg_s
-		FROM assigned a, graded g, scores s
-	`, classID).Scan(&completionRate, &avgScore)
+// counted are the event types folded into the class counters.
+var counted = map[string]bool{
+	domain.EventTypeAssignmentAssigned:  true,
+	domain.EventTypeSubmissionCreated:   true,
+	domain.EventTypeSubmissionGraded:    true,
+	domain.EventTypeAssignmentExcused:   true,
+	domain.EventTypeAssignmentUnexcused: true,
+}
+
+// ApplyEvent folds one processed event into its class counters inside tx, so the counters
+// commit together with the rest of the event's updates. Applying the same event twice is a no-op.
+func (s *Service) ApplyEvent(ctx context.Context, tx pgx.Tx, eventDBID int64, eventType string, in *domain.IncomingEvent) error {
+	if in.ClassID == "" || !counted[eventType] {
+		return nil
+	}
+	c := domain.RollupContribution{
+		EventDBID:    eventDBID,
+		ClassID:      in.ClassID,
+		StudentID:    in.StudentID,
+		AssignmentID: in.AssignmentID,
+		EventType:    eventType,
+	}
+	if eventType == domain.EventTypeSubmissionGraded && in.NormalizedScore != nil {
+		// Averages stay on the percentage scale whatever scale the score was reported on.
+		pct := *in.NormalizedScore * 100
+		c.Score = &pct
+	}
+	repo := s.rollups.WithTx(tx)
+	applied, err := repo.InsertContribution(ctx, c)
+	if err != nil || !applied {
+		return err
+	}
+
+	delta := domain.RollupCounters{ClassID: c.ClassID}
+	if c.Score != nil {
+		delta.ScoreSum = *c.Score
+		delta.ScoreCount = 1
+	}
+	// Events without an assignment count towards the average but not the distinct pairs.
+	if c.AssignmentID != "" {
+		before, err := repo.LockPair(ctx, c.ClassID, c.StudentID, c.AssignmentID)
+		if err != nil {
+			return err
+		}
+		after := withTiming(applyToPair(before, c.EventType, 1), c.EventType, in.Timestamp, in.DueAt)
+		if excusal(c.EventType) {
+			// Excusals may arrive out of order; the latest by timestamp decides.
+			if after, err = repo.PairFromContributions(ctx, c.ClassID, c.StudentID, c.AssignmentID); err != nil {
+				return err
+			}
+		}
+		after.Status = pairStatus(after, time.Now())
+		if err := repo.SavePair(ctx, after); err != nil {
+			return err
+		}
+		delta.AssignedPairs, delta.GradedPairs = pairDelta(before, after)
+	}
+	return repo.AddCounters(ctx, delta)
+}
+
+// RetractEvent takes a retracted event's contribution back out of its class counters inside tx.
+// An event that never contributed is a no-op.
+func (s *Service) RetractEvent(ctx context.Context, tx pgx.Tx, eventDBID int64) error {
+	repo := s.rollups.WithTx(tx)
+	c, err := repo.DeleteContribution(ctx, eventDBID)
+	if err != nil || c == nil {
+		return err
+	}
+	delta := domain.RollupCounters{ClassID: c.ClassID}
+	if c.Score != nil {
+		delta.ScoreSum = -*c.Score
+		delta.ScoreCount = -1
+	}
+	if c.AssignmentID != "" {
+		before, err := repo.LockPair(ctx, c.ClassID, c.StudentID, c.AssignmentID)
+		if err != nil {
+			return err
+		}
+		// Derived from what is left rather than applyToPair(-1): another event may still assign the pair.
+		after, err := repo.PairFromContributions(ctx, c.ClassID, c.StudentID, c.AssignmentID)
+		if err != nil {
+			return err
+		}
+		after.Status = pairStatus(after, time.Now())
+		if err := repo.SavePair(ctx, after); err != nil {
+			return err
+		}
+		delta.AssignedPairs, delta.GradedPairs = pairDelta(before, after)
+	}
+	return repo.AddCounters(ctx, delta)
+}
+
+// CorrectScore replaces the score a graded event contributed to its class average inside tx.
+func (s *Service) CorrectScore(ctx context.Context, tx pgx.Tx, eventDBID int64, normalizedScore float64) error {
+	repo := s.rollups.WithTx(tx)
+	pct := normalizedScore * 100
+	old, err := repo.SetContributionScore(ctx, eventDBID, pct)
+	if err != nil || old == nil {
+		return err
+	}
+	delta := domain.RollupCounters{ClassID: old.ClassID, ScoreSum: pct}
+	if old.Score != nil {
+		delta.ScoreSum -= *old.Score
+	} else {
+		delta.ScoreCount = 1
+	}
+	return repo.AddCounters(ctx, delta)
+}
+
+// MarkMissing moves the class's pairs that are past due at now without a submission to missing inside tx,
+// and returns how many changed. The counters are unaffected; risk flags are the caller's to recompute.
+func (s *Service) MarkMissing(ctx context.Context, tx pgx.Tx, classID string, now time.Time) (int64, error) {
+	return s.rollups.WithTx(tx).MarkMissing(ctx, classID, now)
+}
+
+// ClassesWithOverdue returns the classes with pairs that MarkMissing would change at now.
+func (s *Service) ClassesWithOverdue(ctx context.Context, now time.Time) ([]string, error) {
+	return s.rollups.ClassesWithOverdue(ctx, now)
+}
+
+// RecomputeForClass refreshes class_rollups from the incrementally maintained counters inside tx.
+func (s *Service) RecomputeForClass(ctx context.Context, tx pgx.Tx, classID string) error {
+	return recompute(ctx, s.rollups.WithTx(tx), classID)
+}
+
+// RebuildForClass recomputes the class counters from the full event history. It is the
+// repair path for counters that drifted; normal processing never needs it.
+func (s *Service) RebuildForClass(ctx context.Context, classID string) error {
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
-	// Clamp to [0, 1] to satisfy DB check constraint (avoids float rounding > 1)
+	defer tx.Rollback(ctx)
+
+	repo := s.rollups.WithTx(tx)
+	if err := repo.RebuildCounters(ctx, classID); err != nil {
+		return err
+	}
+	if err := recompute(ctx, repo, classID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func recompute(ctx context.Context, repo *storage.RollupsRepo, classID string) error {
+	counters, err := repo.GetCounters(ctx, classID)
+	if err != nil {
+		return err
+	}
+	completionRate, avgScore := rollupFromCounters(counters)
+	return repo.UpsertClassRollup(ctx, classID, completionRate, avgScore)
+}
+
+// applyToPair returns the pair after adding (sign = 1) or removing (sign = -1) one event.
+func applyToPair(p domain.RollupPair, eventType string, sign int) domain.RollupPair {
+	switch eventType {
+	case domain.EventTypeAssignmentAssigned:
+		p.Assigned = sign > 0
+	case domain.EventTypeSubmissionGraded:
+		p.GradedCount += sign
+		if p.GradedCount < 0 {
+			p.GradedCount = 0
+		}
+	}
+	return p
+}
+
+// withTiming records when the pair was assigned (and due) or first submitted after an event at time at.
+// The latest assignment event sets the due date; the earliest submission, created or graded, dates the submission.
+func withTiming(p domain.RollupPair, eventType string, at time.Time, dueAt *time.Time) domain.RollupPair {
+	switch eventType {
+	case domain.EventTypeAssignmentAssigned:
+		if p.AssignedAt == nil || !at.Before(*p.AssignedAt) {
+			p.AssignedAt, p.DueAt = &at, dueAt
+		}
+	case domain.EventTypeSubmissionCreated, domain.EventTypeSubmissionGraded:
+		if p.SubmittedAt == nil || at.Before(*p.SubmittedAt) {
+			p.SubmittedAt = &at
+		}
+	}
+	return p
+}
+
+// pairStatus derives the pair's assignment status at now. Keep it in line with storage's pairStatusSQL.
+func pairStatus(p domain.RollupPair, now time.Time) string {
+	switch {
+	case p.SubmittedAt != nil && p.DueAt != nil && p.SubmittedAt.After(*p.DueAt):
+		return domain.AssignmentLate
+	case p.SubmittedAt != nil:
+		return domain.AssignmentOnTime
+	case p.DueAt != nil && !now.Before(*p.DueAt):
+		return domain.AssignmentMissing
+	}
+	return domain.AssignmentNotYetDue
+}
+
+func excusal(eventType string) bool {
+	return eventType == domain.EventTypeAssignmentExcused || eventType == domain.EventTypeAssignmentUnexcused
+}
+
+// pairDelta is how much the distinct assigned and graded pair counts change between two states of a pair.
+// An excused pair counts in neither.
+func pairDelta(before, after domain.RollupPair) (assigned, graded int64) {
+	return pairCount(after.Assigned && !after.Excused) - pairCount(before.Assigned && !before.Excused),
+		pairCount(after.GradedCount > 0 && !after.Excused) - pairCount(before.GradedCount > 0 && !before.Excused)
+}
+
+func pairCount(counted bool) int64 {
+	if counted {
+		return 1
+	}
+	return 0
+}
+
+// rollupFromCounters derives the completion rate (graded pairs over assigned pairs) and the average score.
+func rollupFromCounters(c domain.RollupCounters) (completionRate float64, avgScore *float64) {
+	if c.AssignedPairs > 0 {
+		completionRate = float64(c.GradedPairs) / float64(c.AssignedPairs)
+	}
+	// Clamp to [0, 1] to satisfy DB check constraint (graded pairs need not have been assigned)
 	completionRate = math.Max(0, math.Min(1, completionRate))
-	return s.rollups.UpsertClassRollup(ctx, classID, completionRate, avgScore)
+	if c.ScoreCount > 0 {
+		avg := c.ScoreSum / float64(c.ScoreCount)
+		avgScore = &avg
+	}
+	return completionRate, avgScore
 }
@@ -0,0 +1,139 @@
+package rollups
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func TestPairDelta(t *testing.T) {
+	assigned, graded := domain.EventTypeAssignmentAssigned, domain.EventTypeSubmissionGraded
+	tests := []struct {
+		name         string
+		before       domain.RollupPair
+		events       []string
+		excused      bool // pair state after the events
+		wantAssigned int64
+		wantGraded   int64
+	}{
+		{name: "first assignment", events: []string{assigned}, wantAssigned: 1},
+		{name: "re-assigned", before: domain.RollupPair{Assigned: true}, events: []string{assigned}},
+		{name: "first grade", before: domain.RollupPair{Assigned: true}, events: []string{graded}, wantGraded: 1},
+		{name: "regrade counts once", before: domain.RollupPair{Assigned: true, GradedCount: 1}, events: []string{graded}},
+		{name: "graded before assigned", events: []string{graded, assigned}, wantAssigned: 1, wantGraded: 1},
+		{name: "excused", before: domain.RollupPair{Assigned: true}, excused: true, wantAssigned: -1},
+		{name: "excused after grading", before: domain.RollupPair{Assigned: true, GradedCount: 1}, excused: true, wantAssigned: -1, wantGraded: -1},
+		{name: "assigned while excused", before: domain.RollupPair{Excused: true}, events: []string{assigned}, excused: true},
+		{name: "graded while excused", before: domain.RollupPair{Assigned: true, Excused: true}, events: []string{graded}, excused: true},
+		{name: "un-excused", before: domain.RollupPair{Assigned: true, GradedCount: 1, Excused: true}, wantAssigned: 1, wantGraded: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := tt.before
+			for _, ev := range tt.events {
+				after = applyToPair(after, ev, 1)
+			}
+			after.Excused = tt.excused
+			a, g := pairDelta(tt.before, after)
+			if a != tt.wantAssigned || g != tt.wantGraded {
+				t.Errorf("pairDelta() = (%d, %d), want (%d, %d)", a, g, tt.wantAssigned, tt.wantGraded)
+			}
+		})
+	}
+}
+
+func TestWithTiming(t *testing.T) {
+	mon := time.Date(2024, 9, 2, 9, 0, 0, 0, time.UTC)
+	tue, wed, fri := mon.AddDate(0, 0, 1), mon.AddDate(0, 0, 2), mon.AddDate(0, 0, 4)
+	type step struct {
+		eventType string
+		at        time.Time
+		dueAt     *time.Time
+	}
+	tests := []struct {
+		name          string
+		steps         []step
+		wantDue       *time.Time
+		wantSubmitted *time.Time
+	}{
+		{name: "assigned", steps: []step{{domain.EventTypeAssignmentAssigned, mon, &fri}}, wantDue: &fri},
+		{name: "due date moved", steps: []step{{domain.EventTypeAssignmentAssigned, mon, &wed}, {domain.EventTypeAssignmentAssigned, tue, &fri}}, wantDue: &fri},
+		{name: "older reassignment arrives late", steps: []step{{domain.EventTypeAssignmentAssigned, tue, &fri}, {domain.EventTypeAssignmentAssigned, mon, &wed}}, wantDue: &fri},
+		{name: "due date removed", steps: []step{{domain.EventTypeAssignmentAssigned, mon, &fri}, {domain.EventTypeAssignmentAssigned, tue, nil}}},
+		{name: "first submission counts", steps: []step{{domain.EventTypeSubmissionCreated, tue, nil}, {domain.EventTypeSubmissionGraded, fri, nil}}, wantSubmitted: &tue},
+		{name: "created after graded", steps: []step{{domain.EventTypeSubmissionGraded, fri, nil}, {domain.EventTypeSubmissionCreated, wed, nil}}, wantSubmitted: &wed},
+		{name: "excusal ignored", steps: []step{{domain.EventTypeAssignmentExcused, mon, nil}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p domain.RollupPair
+			for _, s := range tt.steps {
+				p = withTiming(p, s.eventType, s.at, s.dueAt)
+			}
+			if !timeEqual(p.DueAt, tt.wantDue) {
+				t.Errorf("DueAt = %v, want %v", p.DueAt, tt.wantDue)
+			}
+			if !timeEqual(p.SubmittedAt, tt.wantSubmitted) {
+				t.Errorf("SubmittedAt = %v, want %v", p.SubmittedAt, tt.wantSubmitted)
+			}
+		})
+	}
+}
+
+func TestPairStatus(t *testing.T) {
+	due := time.Date(2024, 9, 6, 23, 59, 0, 0, time.UTC)
+	before, after := due.Add(-time.Hour), due.Add(time.Hour)
+	tests := []struct {
+		name string
+		pair domain.RollupPair
+		now  time.Time
+		want string
+	}{
+		{name: "not yet due", pair: domain.RollupPair{DueAt: &due}, now: before, want: domain.AssignmentNotYetDue},
+		{name: "missing", pair: domain.RollupPair{DueAt: &due}, now: after, want: domain.AssignmentMissing},
+		{name: "missing at the due time", pair: domain.RollupPair{DueAt: &due}, now: due, want: domain.AssignmentMissing},
+		{name: "on time", pair: domain.RollupPair{DueAt: &due, SubmittedAt: &before}, now: after, want: domain.AssignmentOnTime},
+		{name: "submitted at the due time", pair: domain.RollupPair{DueAt: &due, SubmittedAt: &due}, now: after, want: domain.AssignmentOnTime},
+		{name: "late", pair: domain.RollupPair{DueAt: &due, SubmittedAt: &after}, now: after, want: domain.AssignmentLate},
+		{name: "no due date", pair: domain.RollupPair{}, now: after, want: domain.AssignmentNotYetDue},
+		{name: "no due date submitted", pair: domain.RollupPair{SubmittedAt: &after}, now: after, want: domain.AssignmentOnTime},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pairStatus(tt.pair, tt.now); got != tt.want {
+				t.Errorf("pairStatus() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func timeEqual(a, b *time.Time) bool {
+	return (a == nil) == (b == nil) && (a == nil || a.Equal(*b))
+}
+
+func TestRollupFromCounters(t *testing.T) {
+	tests := []struct {
+		name           string
+		counters       domain.RollupCounters
+		wantCompletion float64
+		wantAvg        *float64
+	}{
+		{name: "empty class", counters: domain.RollupCounters{}},
+		{name: "half complete", counters: domain.RollupCounters{AssignedPairs: 4, GradedPairs: 2, ScoreSum: 170, ScoreCount: 2}, wantCompletion: 0.5, wantAvg: ptrFloat64(85)},
+		{name: "graded without assignment clamps", counters: domain.RollupCounters{AssignedPairs: 1, GradedPairs: 3, ScoreSum: 90, ScoreCount: 3}, wantCompletion: 1, wantAvg: ptrFloat64(30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completion, avg := rollupFromCounters(tt.counters)
+			if completion != tt.wantCompletion {
+				t.Errorf("completion = %v, want %v", completion, tt.wantCompletion)
+			}
+			if (avg == nil) != (tt.wantAvg == nil) || (avg != nil && *avg != *tt.wantAvg) {
+				t.Errorf("avg = %v, want %v", avg, tt.wantAvg)
+			}
+		})
+	}
+}
+
+func ptrFloat64(f float64) *float64 { return &f }
@@ -0,0 +1,124 @@
+// Package scales normalizes scores reported on different grading scales to 0..1.
+package scales
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Scale IDs accepted in IncomingEvent.Scale
+const (
+	Percentage   = "percentage"
+	Points       = "points"
+	Proficiency4 = "proficiency_4"
+	LetterGrade  = "letter"
+	PassFail     = "pass_fail"
+	DefaultScale = Percentage
+)
+
+var ErrOutOfScale = errors.New("score outside scale")
+
+// Scale maps a score or grade on the scale to 0..1.
+type Scale interface {
+	ID() string
+	// Normalize errors if neither score nor grade is given, or the value is not on the scale.
+	Normalize(score *float64, grade string) (float64, error)
+}
+
+// rangeScale is a numeric scale from 0 to Max.
+type rangeScale struct {
+	id  string
+	max float64
+}
+
+func (s rangeScale) ID() string { return s.id }
+
+func (s rangeScale) Normalize(score *float64, grade string) (float64, error) {
+	if grade != "" {
+		return 0, fmt.Errorf("%w: %s takes a numeric score, not a grade", ErrOutOfScale, s.id)
+	}
+	if score == nil {
+		return 0, fmt.Errorf("%w: %s requires a score", ErrOutOfScale, s.id)
+	}
+	if *score < 0 || *score > s.max {
+		return 0, fmt.Errorf("%w: %v is not within 0..%v on %s", ErrOutOfScale, *score, s.max, s.id)
+	}
+	return *score / s.max, nil
+}
+
+// gradeScale is a set of named grades worth 0..max points each. A numeric score in 0..max is accepted too,
+// or with discrete set, only a score equal to one of the grades' points (pass_fail takes 1 or 0, never 0.5).
+type gradeScale struct {
+	id       string
+	max      float64
+	grades   map[string]float64
+	discrete bool
+}
+
+func (s gradeScale) ID() string { return s.id }
+
+func (s gradeScale) Normalize(score *float64, grade string) (float64, error) {
+	if grade != "" {
+		if score != nil {
+			return 0, fmt.Errorf("%w: give either a score or a grade on %s, not both", ErrOutOfScale, s.id)
+		}
+		points, ok := s.grades[strings.ToUpper(strings.TrimSpace(grade))]
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown grade %q on %s", ErrOutOfScale, grade, s.id)
+		}
+		return points / s.max, nil
+	}
+	if s.discrete && score != nil && !s.isGradeValue(*score) {
+		return 0, fmt.Errorf("%w: %v is not a grade on %s", ErrOutOfScale, *score, s.id)
+	}
+	return rangeScale{id: s.id, max: s.max}.Normalize(score, "")
+}
+
+func (s gradeScale) isGradeValue(score float64) bool {
+	for _, points := range s.grades {
+		if score == points {
+			return true
+		}
+	}
+	return false
+}
+
+var registry = map[string]Scale{
+	Percentage:   rangeScale{id: Percentage, max: 100},
+	Proficiency4: rangeScale{id: Proficiency4, max: 4},
+	// Letter grades normalize by their GPA points; A+ is capped at 4.0.
+	LetterGrade: gradeScale{id: LetterGrade, max: 4, grades: map[string]float64{
+		"A+": 4.0, "A": 4.0, "A-": 3.7,
+		"B+": 3.3, "B": 3.0, "B-": 2.7,
+		"C+": 2.3, "C": 2.0, "C-": 1.7,
+		"D+": 1.3, "D": 1.0, "D-": 0.7,
+		"F": 0,
+	}},
+	PassFail: gradeScale{id: PassFail, max: 1, grades: map[string]float64{"PASS": 1, "FAIL": 0}, discrete: true},
+}
+
+// For returns the scale an event declared: pointsPossible selects the points scale (scale must then be
+// empty or "points"); otherwise the named scale, or DefaultScale when none is named.
+func For(scale string, pointsPossible *float64) (Scale, error) {
+	if pointsPossible != nil {
+		if scale != "" && scale != Points {
+			return nil, fmt.Errorf("%w: points_possible cannot be combined with scale %q", ErrOutOfScale, scale)
+		}
+		if *pointsPossible <= 0 {
+			return nil, fmt.Errorf("%w: points_possible must be positive", ErrOutOfScale)
+		}
+		return rangeScale{id: Points, max: *pointsPossible}, nil
+	}
+	if scale == Points {
+		return nil, fmt.Errorf("%w: scale points requires points_possible", ErrOutOfScale)
+	}
+	if scale == "" {
+		scale = DefaultScale
+	}
+	s, ok := registry[scale]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown scale %q", ErrOutOfScale, scale)
+	}
+	return s, nil
+}
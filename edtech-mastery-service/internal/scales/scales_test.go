@@ -0,0 +1,64 @@
+package scales
+
+import (
+	"errors"
+	"testing"
+)
+
+func ptrFloat64(f float64) *float64 { return &f }
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name           string
+		scale          string
+		pointsPossible *float64
+		score          *float64
+		grade          string
+		want           float64
+		wantErr        bool
+	}{
+		{name: "percentage default", score: ptrFloat64(72), want: 0.72},
+		{name: "percentage above 100", scale: Percentage, score: ptrFloat64(101), wantErr: true},
+		{name: "negative score", score: ptrFloat64(-1), wantErr: true},
+		{name: "points", pointsPossible: ptrFloat64(20), score: ptrFloat64(20), want: 1},
+		{name: "points named explicitly", scale: Points, pointsPossible: ptrFloat64(40), score: ptrFloat64(10), want: 0.25},
+		{name: "points without points possible", scale: Points, score: ptrFloat64(10), wantErr: true},
+		{name: "points possible with another scale", scale: Proficiency4, pointsPossible: ptrFloat64(4), score: ptrFloat64(3), wantErr: true},
+		{name: "zero points possible", pointsPossible: ptrFloat64(0), score: ptrFloat64(0), wantErr: true},
+		{name: "proficiency", scale: Proficiency4, score: ptrFloat64(2), want: 0.5},
+		{name: "proficiency above 4", scale: Proficiency4, score: ptrFloat64(5), wantErr: true},
+		{name: "letter grade", scale: LetterGrade, grade: "A-", want: 3.7 / 4},
+		{name: "letter grade lower case", scale: LetterGrade, grade: " c ", want: 0.5},
+		{name: "letter A+ capped", scale: LetterGrade, grade: "A+", want: 1},
+		{name: "letter GPA points", scale: LetterGrade, score: ptrFloat64(3), want: 0.75},
+		{name: "unknown letter", scale: LetterGrade, grade: "E", wantErr: true},
+		{name: "grade and score", scale: LetterGrade, grade: "A", score: ptrFloat64(4), wantErr: true},
+		{name: "fail", scale: PassFail, grade: "FAIL", want: 0},
+		{name: "pass as score", scale: PassFail, score: ptrFloat64(1), want: 1},
+		{name: "fractional pass/fail score", scale: PassFail, score: ptrFloat64(0.5), wantErr: true},
+		{name: "grade on numeric scale", grade: "A", wantErr: true},
+		{name: "unknown scale", scale: "stanine", score: ptrFloat64(5), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalize(tt.scale, tt.pointsPossible, tt.score, tt.grade)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrOutOfScale) {
+				t.Errorf("err = %v, want ErrOutOfScale", err)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("normalized = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func normalize(scale string, pointsPossible, score *float64, grade string) (float64, error) {
+	s, err := For(scale, pointsPossible)
+	if err != nil {
+		return 0, err
+	}
+	return s.Normalize(score, grade)
+}
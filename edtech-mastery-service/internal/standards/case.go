@@ -0,0 +1,282 @@
+package standards
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+var ErrInvalidPackage = errors.New("invalid CASE package")
+
+// caseAssociationChildOf is the CASE association that builds the hierarchy; origin is the child.
+const caseAssociationChildOf = "isChildOf"
+
+// casePackage is the part of a CASE 1.0 CFPackage the importer reads.
+type casePackage struct {
+	CFDocument struct {
+		Identifier  string `json:"identifier"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Version     string `json:"version"`
+	} `json:"CFDocument"`
+	CFItems        []caseItem        `json:"CFItems"`
+	CFAssociations []caseAssociation `json:"CFAssociations"`
+}
+
+type caseItem struct {
+	Identifier           string `json:"identifier"`
+	FullStatement        string `json:"fullStatement"`
+	AbbreviatedStatement string `json:"abbreviatedStatement"`
+	HumanCodingScheme    string `json:"humanCodingScheme"`
+	ListEnumeration      string `json:"listEnumeration"`
+	CFItemType           string `json:"CFItemType"`
+}
+
+type caseAssociation struct {
+	Identifier         string   `json:"identifier"`
+	AssociationType    string   `json:"associationType"`
+	OriginNodeURI      caseLink `json:"originNodeURI"`
+	DestinationNodeURI caseLink `json:"destinationNodeURI"`
+	SequenceNumber     *int     `json:"sequenceNumber"`
+}
+
+type caseLink struct {
+	Identifier string `json:"identifier"`
+	URI        string `json:"uri"`
+}
+
+// catalogPackage is a CASE package translated to the catalog: items are ordered parents first.
+type catalogPackage struct {
+	Framework    domain.Framework
+	Items        []domain.StandardItem
+	Associations []domain.StandardAssociation
+	Warnings     []string
+}
+
+// parseCASE reads a CASE package (bare, or wrapped in {"CFPackage": ...}) and maps it to the catalog:
+// the CFDocument becomes the framework and each CFItem a standard (see catalogID for its ID).
+// isChildOf associations set the parents; every other association is kept as a StandardAssociation.
+func parseCASE(r io.Reader) (*catalogPackage, error) {
+	var doc struct {
+		casePackage
+		CFPackage *casePackage `json:"CFPackage"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPackage, err)
+	}
+	if doc.CFPackage != nil {
+		return doc.CFPackage.toCatalog()
+	}
+	return doc.casePackage.toCatalog()
+}
+
+func (pkg *casePackage) toCatalog() (*catalogPackage, error) {
+	doc := pkg.CFDocument
+	if doc.Identifier == "" || doc.Title == "" {
+		return nil, fmt.Errorf("%w: CFDocument identifier and title required", ErrInvalidPackage)
+	}
+	out := &catalogPackage{Framework: domain.Framework{
+		ID: doc.Identifier, Name: doc.Title, Description: doc.Description, Version: doc.Version,
+	}}
+
+	items := make(map[string]caseItem, len(pkg.CFItems))
+	index := make(map[string]int, len(pkg.CFItems))
+	for i, it := range pkg.CFItems {
+		if it.Identifier == "" {
+			return nil, fmt.Errorf("%w: CFItems[%d] has no identifier", ErrInvalidPackage, i)
+		}
+		if _, dup := items[it.Identifier]; dup {
+			return nil, fmt.Errorf("%w: duplicate CFItem %s", ErrInvalidPackage, it.Identifier)
+		}
+		items[it.Identifier] = it
+		index[it.Identifier] = i
+	}
+	ids := make(map[string]string, len(items))
+	owner := make(map[string]string, len(items))
+	for _, it := range pkg.CFItems {
+		id := catalogID(it)
+		if prev, dup := owner[id]; dup {
+			return nil, fmt.Errorf("%w: CFItems %s and %s share the standard ID %s", ErrInvalidPackage, prev, it.Identifier, id)
+		}
+		owner[id] = it.Identifier
+		ids[it.Identifier] = id
+	}
+	// linkID is the catalog ID of an association end: the standard's ID for items in the package, else as given.
+	linkID := func(identifier string) string {
+		if id, ok := ids[identifier]; ok {
+			return id
+		}
+		return identifier
+	}
+
+	// Hierarchy: visit isChildOf associations in sequence order so the first parent wins.
+	assocs := append([]caseAssociation(nil), pkg.CFAssociations...)
+	sort.SliceStable(assocs, func(i, j int) bool { return seq(assocs[i]) < seq(assocs[j]) })
+	parents := make(map[string]string)
+	positions := make(map[string]int)
+	for _, a := range assocs {
+		origin, dest := a.OriginNodeURI.Identifier, a.DestinationNodeURI.Identifier
+		if _, ok := items[origin]; !ok {
+			continue
+		}
+		if a.AssociationType != caseAssociationChildOf {
+			if a.Identifier == "" || dest == "" {
+				out.Warnings = append(out.Warnings, fmt.Sprintf("skipped %s association from %s without identifier or destination", a.AssociationType, origin))
+				continue
+			}
+			out.Associations = append(out.Associations, domain.StandardAssociation{
+				ID: a.Identifier, FrameworkID: doc.Identifier, AssociationType: a.AssociationType,
+				OriginID: ids[origin], DestinationID: linkID(dest),
+			})
+			continue
+		}
+		if _, seen := positions[origin]; seen {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("%s has several parents; kept the first", origin))
+			continue
+		}
+		positions[origin] = seq(a)
+		switch _, isItem := items[dest]; {
+		case isItem:
+			parents[origin] = dest
+		case dest != doc.Identifier:
+			out.Warnings = append(out.Warnings, fmt.Sprintf("%s is a child of unknown node %s; imported as a root", origin, dest))
+		}
+	}
+
+	depth := make(map[string]int, len(items))
+	var depthOf func(id string, seen map[string]bool) (int, error)
+	depthOf = func(id string, seen map[string]bool) (int, error) {
+		if d, ok := depth[id]; ok {
+			return d, nil
+		}
+		parent, ok := parents[id]
+		if !ok {
+			depth[id] = 0
+			return 0, nil
+		}
+		if seen[id] {
+			return 0, fmt.Errorf("%w: isChildOf cycle through %s", ErrInvalidPackage, id)
+		}
+		seen[id] = true
+		d, err := depthOf(parent, seen)
+		if err != nil {
+			return 0, err
+		}
+		depth[id] = d + 1
+		return d + 1, nil
+	}
+	for id := range items {
+		if _, err := depthOf(id, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, it := range pkg.CFItems {
+		item := domain.StandardItem{
+			ID:          it.Identifier,
+			FrameworkID: doc.Identifier,
+			Code:        it.HumanCodingScheme,
+			Description: it.FullStatement,
+			Position:    index[it.Identifier],
+		}
+		if item.Description == "" {
+			item.Description = it.AbbreviatedStatement
+		}
+		if p, ok := positions[it.Identifier]; ok && p != noSequence {
+			item.Position = p
+		} else if n, err := strconv.Atoi(strings.TrimSpace(it.ListEnumeration)); err == nil {
+			item.Position = n
+		}
+		if parent, ok := parents[it.Identifier]; ok {
+			p := parent
+			item.ParentID = &p
+		}
+		out.Items = append(out.Items, item)
+	}
+	sort.SliceStable(out.Items, func(i, j int) bool {
+		di, dj := depth[out.Items[i].ID], depth[out.Items[j].ID]
+		if di != dj {
+			return di < dj
+		}
+		return out.Items[i].Position < out.Items[j].Position
+	})
+
+	// Levels top-down, so a child is never at a broader level than its parent.
+	levels := make(map[string]string, len(out.Items))
+	for i := range out.Items {
+		item := &out.Items[i]
+		minRank := 0
+		if item.ParentID != nil {
+			minRank = domain.StandardLevelRank(levels[*item.ParentID])
+		}
+		rank := levelRank(items[item.ID].CFItemType)
+		if rank < 0 {
+			rank = depth[item.ID]
+			if rank > domain.StandardLevelRank(domain.StandardLevelStandard) {
+				rank = domain.StandardLevelRank(domain.StandardLevelStandard)
+			}
+		}
+		if rank < minRank {
+			rank = minRank
+		}
+		item.Level = domain.StandardLevels[rank]
+		levels[item.ID] = item.Level
+	}
+
+	// Everything above is keyed by CASE identifier; switch to the catalog IDs.
+	for i := range out.Items {
+		item := &out.Items[i]
+		item.ID = ids[item.ID]
+		if item.ParentID != nil {
+			p := ids[*item.ParentID]
+			item.ParentID = &p
+		}
+	}
+	return out, nil
+}
+
+// catalogID is the standard ID of a CFItem: its humanCodingScheme (e.g. "6.RP.A.1"), which is what events
+// send in standard_ids, or its CASE identifier (often a UUID) for items without one.
+func catalogID(it caseItem) string {
+	if code := strings.TrimSpace(it.HumanCodingScheme); code != "" {
+		return code
+	}
+	return it.Identifier
+}
+
+// noSequence sorts associations without a sequenceNumber after numbered ones.
+const noSequence = math.MaxInt
+
+func seq(a caseAssociation) int {
+	if a.SequenceNumber == nil {
+		return noSequence
+	}
+	return *a.SequenceNumber
+}
+
+// levelRank maps a publisher's CFItemType onto the catalog levels, or -1 if it is not recognized.
+func levelRank(itemType string) int {
+	t := strings.ToLower(itemType)
+	switch {
+	case t == "":
+		return -1
+	case strings.Contains(t, "subject") || strings.Contains(t, "course") || strings.Contains(t, "grade"):
+		return domain.StandardLevelRank(domain.StandardLevelSubject)
+	case strings.Contains(t, "cluster") || strings.Contains(t, "sub-strand") || strings.Contains(t, "substrand"):
+		return domain.StandardLevelRank(domain.StandardLevelCluster)
+	case strings.Contains(t, "domain") || strings.Contains(t, "strand") || strings.Contains(t, "conceptual category"):
+		return domain.StandardLevelRank(domain.StandardLevelDomain)
+	case strings.Contains(t, "standard") || strings.Contains(t, "component") || strings.Contains(t, "benchmark") ||
+		strings.Contains(t, "indicator") || strings.Contains(t, "objective") || strings.Contains(t, "expectation"):
+		return domain.StandardLevelRank(domain.StandardLevelStandard)
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,243 @@
+package standards
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func loadCASE(t *testing.T, name string) *catalogPackage {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	pkg, err := parseCASE(f)
+	if err != nil {
+		t.Fatalf("parseCASE(%s): %v", name, err)
+	}
+	return pkg
+}
+
+func TestParseCASE(t *testing.T) {
+	pkg := loadCASE(t, "case_v1.json")
+	if pkg.Framework.ID != "math-6" || pkg.Framework.Version != "2023" {
+		t.Fatalf("framework = %+v", pkg.Framework)
+	}
+	if len(pkg.Warnings) != 0 {
+		t.Errorf("warnings = %v, want none", pkg.Warnings)
+	}
+
+	type row struct {
+		id, parent, level string
+		position          int
+	}
+	var got []row
+	for _, it := range pkg.Items {
+		got = append(got, row{it.ID, ptrValue(it.ParentID), it.Level, it.Position})
+	}
+	// Keyed by humanCodingScheme; parents first, siblings by sequenceNumber; the document itself is not an item.
+	want := []row{
+		{"6", "", domain.StandardLevelSubject, 1},
+		{"6.RP", "6", domain.StandardLevelDomain, 1},
+		{"6.NS", "6", domain.StandardLevelDomain, 2},
+		{"6.RP.A", "6.RP", domain.StandardLevelCluster, 1},
+		{"6.NS.1", "6.NS", domain.StandardLevelStandard, 1},
+		{"6.RP.A.1", "6.RP.A", domain.StandardLevelStandard, 1},
+		{"6.RP.A.2", "6.RP.A", domain.StandardLevelStandard, 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("items =\n%v\nwant\n%v", got, want)
+	}
+	for _, it := range pkg.Items {
+		if it.ID == "6.NS.1" && it.Description != "Divide fractions by fractions." {
+			t.Errorf("description = %q, want the abbreviatedStatement", it.Description)
+		}
+	}
+
+	wantAssocs := []domain.StandardAssociation{
+		{ID: "x-rp-a-1", FrameworkID: "math-6", AssociationType: "exactMatchOf", OriginID: "6.RP.A.1", DestinationID: "ccss.math.6.rp.a.1"},
+		{ID: "x-ns-1", FrameworkID: "math-6", AssociationType: "isRelatedTo", OriginID: "6.NS.1", DestinationID: "math-5.nf.7"},
+	}
+	if !reflect.DeepEqual(pkg.Associations, wantAssocs) {
+		t.Errorf("associations = %+v, want %+v", pkg.Associations, wantAssocs)
+	}
+}
+
+func TestParseCASEWrappedPackage(t *testing.T) {
+	pkg := loadCASE(t, "case_v2.json")
+	if pkg.Framework.ID != "math-6" || pkg.Framework.Version != "2024" || len(pkg.Items) != 7 {
+		t.Fatalf("framework = %+v, %d items", pkg.Framework, len(pkg.Items))
+	}
+}
+
+func TestParseCASEUUIDIdentifiers(t *testing.T) {
+	// Real CASE packages identify items by UUID; events tag standards by their code.
+	pkg := loadCASE(t, "case_uuid.json")
+	byID := make(map[string]domain.StandardItem, len(pkg.Items))
+	for _, it := range pkg.Items {
+		byID[it.ID] = it
+	}
+	item, ok := byID["6.RP.A.1"]
+	if !ok {
+		t.Fatalf("no standard 6.RP.A.1 among %v", pkg.Items)
+	}
+	if ptrValue(item.ParentID) != "6.RP.A" || ptrValue(byID["6.RP.A"].ParentID) != "6.RP" {
+		t.Errorf("ancestry = %q < %q, want 6.RP.A < 6.RP", ptrValue(item.ParentID), ptrValue(byID["6.RP.A"].ParentID))
+	}
+	if root, ok := byID["9b1c3ad2-5f44-4c7e-8a0b-2d6f1e7c9a10"]; !ok || root.Code != "" {
+		t.Errorf("item without humanCodingScheme should keep its identifier, got %v", pkg.Items)
+	}
+	wantAssoc := domain.StandardAssociation{
+		ID: "5e0f8d7c-3b2a-4c19-9e8d-7f6a5b4c3d2e", FrameworkID: pkg.Framework.ID, AssociationType: "exactMatchOf",
+		OriginID: "6.RP.A.1", DestinationID: "c1d2e3f4-0000-4000-8000-000000000001",
+	}
+	if len(pkg.Associations) != 1 || pkg.Associations[0] != wantAssoc {
+		t.Errorf("associations = %+v, want %+v", pkg.Associations, wantAssoc)
+	}
+
+	// An event tagged standard_ids ["6.RP.A.1"] rolls up the imported framework.
+	tree, uncataloged := BuildMasteryTree([]domain.Framework{pkg.Framework}, pkg.Items,
+		[]domain.StandardMastery{{StandardID: "6.RP.A.1", MasteryScore: 0.8}})
+	if len(uncataloged) != 0 || len(tree) != 1 || tree[0].StandardsAssessed != 1 {
+		t.Errorf("mastery tree = %+v, uncataloged %v; want 6.RP.A.1 under %s", tree, uncataloged, pkg.Framework.ID)
+	}
+}
+
+func TestParseCASELevels(t *testing.T) {
+	// Unknown item types fall back to depth, and a child is never broader than its parent.
+	pkg, err := parseCASE(strings.NewReader(`{
+		"CFDocument": {"identifier": "d", "title": "D"},
+		"CFItems": [
+			{"identifier": "a", "CFItemType": "Standard"},
+			{"identifier": "b", "CFItemType": "Domain"},
+			{"identifier": "c"}
+		],
+		"CFAssociations": [
+			{"associationType": "isChildOf", "originNodeURI": {"identifier": "b"}, "destinationNodeURI": {"identifier": "a"}},
+			{"associationType": "isChildOf", "originNodeURI": {"identifier": "c"}, "destinationNodeURI": {"identifier": "b"}}
+		]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, it := range pkg.Items {
+		if it.Level != domain.StandardLevelStandard {
+			t.Errorf("%s level = %s, want %s", it.ID, it.Level, domain.StandardLevelStandard)
+		}
+	}
+}
+
+func TestParseCASEWarnings(t *testing.T) {
+	pkg, err := parseCASE(strings.NewReader(`{
+		"CFDocument": {"identifier": "d", "title": "D"},
+		"CFItems": [{"identifier": "a"}, {"identifier": "b"}, {"identifier": "c"}],
+		"CFAssociations": [
+			{"associationType": "isChildOf", "originNodeURI": {"identifier": "b"}, "destinationNodeURI": {"identifier": "a"}, "sequenceNumber": 2},
+			{"associationType": "isChildOf", "originNodeURI": {"identifier": "b"}, "destinationNodeURI": {"identifier": "d"}, "sequenceNumber": 5},
+			{"associationType": "isChildOf", "originNodeURI": {"identifier": "c"}, "destinationNodeURI": {"identifier": "elsewhere"}},
+			{"associationType": "exactMatchOf", "originNodeURI": {"identifier": "a"}, "destinationNodeURI": {"identifier": "x"}}
+		]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkg.Warnings) != 3 {
+		t.Errorf("warnings = %v, want 3 (extra parent, unknown parent, association without identifier)", pkg.Warnings)
+	}
+	for _, it := range pkg.Items {
+		switch it.ID {
+		case "b":
+			if ptrValue(it.ParentID) != "a" {
+				t.Errorf("b parent = %q, want the first by sequence (a)", ptrValue(it.ParentID))
+			}
+		case "c":
+			if it.ParentID != nil {
+				t.Errorf("c parent = %q, want root", *it.ParentID)
+			}
+		}
+	}
+}
+
+func TestParseCASEErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"not json", `{`},
+		{"no document", `{"CFItems": []}`},
+		{"item without identifier", `{"CFDocument": {"identifier": "d", "title": "D"}, "CFItems": [{"fullStatement": "x"}]}`},
+		{"duplicate item", `{"CFDocument": {"identifier": "d", "title": "D"}, "CFItems": [{"identifier": "a"}, {"identifier": "a"}]}`},
+		{"duplicate code", `{"CFDocument": {"identifier": "d", "title": "D"},
+			"CFItems": [{"identifier": "a", "humanCodingScheme": "6.RP"}, {"identifier": "b", "humanCodingScheme": "6.RP"}]}`},
+		{"code clashes with identifier", `{"CFDocument": {"identifier": "d", "title": "D"},
+			"CFItems": [{"identifier": "a", "humanCodingScheme": "b"}, {"identifier": "b"}]}`},
+		{"cycle", `{"CFDocument": {"identifier": "d", "title": "D"},
+			"CFItems": [{"identifier": "a"}, {"identifier": "b"}],
+			"CFAssociations": [
+				{"associationType": "isChildOf", "originNodeURI": {"identifier": "a"}, "destinationNodeURI": {"identifier": "b"}},
+				{"associationType": "isChildOf", "originNodeURI": {"identifier": "b"}, "destinationNodeURI": {"identifier": "a"}}
+			]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseCASE(strings.NewReader(tt.in)); !errors.Is(err, ErrInvalidPackage) {
+				t.Errorf("err = %v, want ErrInvalidPackage", err)
+			}
+		})
+	}
+}
+
+func TestDiffCatalog(t *testing.T) {
+	v1, v2 := loadCASE(t, "case_v1.json"), loadCASE(t, "case_v2.json")
+
+	fresh := diffCatalog(nil, nil, nil, v1)
+	if !fresh.FrameworkCreated || len(fresh.Added) != len(v1.Items) || fresh.AssociationsAdded != 2 {
+		t.Errorf("first import = %+v", fresh)
+	}
+
+	same := diffCatalog(&v1.Framework, v1.Items, v1.Associations, v1)
+	if len(same.Added)+len(same.Updated)+len(same.Missing) != 0 || same.Unchanged != len(v1.Items) ||
+		same.AssociationsAdded+same.AssociationsUpdated+same.AssociationsRemoved != 0 {
+		t.Errorf("re-import = %+v, want no changes", same)
+	}
+
+	report := diffCatalog(&v1.Framework, v1.Items, v1.Associations, v2)
+	if report.FrameworkCreated || report.Version != "2024" || report.PreviousVersion != "2023" {
+		t.Errorf("versions = %q from %q (created %v)", report.Version, report.PreviousVersion, report.FrameworkCreated)
+	}
+	if !reflect.DeepEqual(report.Added, []string{"6.NS.2"}) {
+		t.Errorf("added = %v", report.Added)
+	}
+	wantUpdated := []domain.StandardChange{{ID: "6.RP.A.2", Fields: []string{"description"}}}
+	if !reflect.DeepEqual(report.Updated, wantUpdated) {
+		t.Errorf("updated = %+v, want %+v", report.Updated, wantUpdated)
+	}
+	if !reflect.DeepEqual(report.Missing, []string{"6.NS.1"}) {
+		t.Errorf("missing = %v", report.Missing)
+	}
+	if report.Unchanged != 5 {
+		t.Errorf("unchanged = %d, want 5", report.Unchanged)
+	}
+	if report.AssociationsAdded != 1 || report.AssociationsUpdated != 1 || report.AssociationsRemoved != 1 {
+		t.Errorf("associations added/updated/removed = %d/%d/%d, want 1/1/1",
+			report.AssociationsAdded, report.AssociationsUpdated, report.AssociationsRemoved)
+	}
+}
+
+func TestCheckOwners(t *testing.T) {
+	pkg := loadCASE(t, "case_v1.json")
+	if err := checkOwners(pkg, nil); err != nil {
+		t.Errorf("new standards: %v", err)
+	}
+	if err := checkOwners(pkg, map[string]string{"6.RP": "math-6", "6.RP.A.1": "math-6"}); err != nil {
+		t.Errorf("re-import: %v", err)
+	}
+	err := checkOwners(pkg, map[string]string{"6.RP": "math-6", "6.NS.1": "ccss-math"})
+	if !errors.Is(err, ErrOtherFramework) || !strings.Contains(err.Error(), "6.NS.1 (in ccss-math)") {
+		t.Errorf("err = %v, want ErrOtherFramework naming 6.NS.1", err)
+	}
+}
@@ -0,0 +1,124 @@
+// Package standards is the standards catalog: frameworks and their subject > domain > cluster > standard hierarchy.
+package standards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+	"github.com/edtech-mastery/student-progress-service/internal/storage"
+)
+
+var (
+	ErrInvalidStandard = errors.New("invalid standard")
+	ErrHasChildren     = errors.New("standard has children")
+	// ErrOtherFramework is returned when an import would take over standards cataloged under another framework.
+	ErrOtherFramework = errors.New("standard belongs to another framework")
+)
+
+type Service struct {
+	uow  *storage.UnitOfWork
+	repo *storage.StandardsRepo
+}
+
+func NewService(uow *storage.UnitOfWork, repo *storage.StandardsRepo) *Service {
+	return &Service{uow: uow, repo: repo}
+}
+
+func (s *Service) Frameworks(ctx context.Context) ([]domain.Framework, error) {
+	return s.repo.ListFrameworks(ctx)
+}
+
+// FrameworkTree returns the framework with its standards nested by parent, or nil if it does not exist.
+func (s *Service) FrameworkTree(ctx context.Context, frameworkID string) (*domain.FrameworkTree, error) {
+	f, err := s.repo.GetFramework(ctx, frameworkID)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	items, err := s.repo.ListStandards(ctx, frameworkID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.FrameworkTree{Framework: *f, Standards: BuildFrameworkTree(items)}, nil
+}
+
+// Standard returns the catalog item, or nil if the ID is not cataloged.
+func (s *Service) Standard(ctx context.Context, id string) (*domain.StandardItem, error) {
+	return s.repo.GetStandard(ctx, id)
+}
+
+func (s *Service) SaveFramework(ctx context.Context, f domain.Framework) error {
+	if f.ID == "" || f.Name == "" {
+		return fmt.Errorf("%w: framework id and name required", ErrInvalidStandard)
+	}
+	return s.repo.UpsertFramework(ctx, f)
+}
+
+// SaveStandard creates or updates a catalog item. Its framework must exist, and its parent (if any) must be
+// in the same framework, at the same or a broader level, and not below the item itself.
+func (s *Service) SaveStandard(ctx context.Context, item domain.StandardItem) error {
+	if item.ID == "" || item.FrameworkID == "" {
+		return fmt.Errorf("%w: id and framework_id required", ErrInvalidStandard)
+	}
+	if domain.StandardLevelRank(item.Level) < 0 {
+		return fmt.Errorf("%w: unknown level %q", ErrInvalidStandard, item.Level)
+	}
+	f, err := s.repo.GetFramework(ctx, item.FrameworkID)
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		return fmt.Errorf("%w: unknown framework %q", ErrInvalidStandard, item.FrameworkID)
+	}
+	if item.ParentID != nil {
+		ancestors, err := s.repo.StandardsWithAncestors(ctx, []string{*item.ParentID})
+		if err != nil {
+			return err
+		}
+		if err := checkParent(item, ancestors); err != nil {
+			return err
+		}
+	}
+	return s.repo.UpsertStandard(ctx, item)
+}
+
+// DeleteStandard removes a leaf from the catalog; it reports false if the standard is not cataloged.
+func (s *Service) DeleteStandard(ctx context.Context, id string) (bool, error) {
+	hasChildren, err := s.repo.HasChildren(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if hasChildren {
+		return false, fmt.Errorf("%w: delete or move its children first", ErrHasChildren)
+	}
+	return s.repo.DeleteStandard(ctx, id)
+}
+
+// checkParent validates item's parent given the parent and its ancestors (as returned by StandardsWithAncestors).
+func checkParent(item domain.StandardItem, parentAndAncestors []domain.StandardItem) error {
+	parentID := *item.ParentID
+	if parentID == item.ID {
+		return fmt.Errorf("%w: a standard cannot be its own parent", ErrInvalidStandard)
+	}
+	var parent *domain.StandardItem
+	for i := range parentAndAncestors {
+		a := &parentAndAncestors[i]
+		if a.ID == parentID {
+			parent = a
+		}
+		if a.ID == item.ID {
+			return fmt.Errorf("%w: parent %q is below %q", ErrInvalidStandard, parentID, item.ID)
+		}
+	}
+	if parent == nil {
+		return fmt.Errorf("%w: unknown parent %q", ErrInvalidStandard, parentID)
+	}
+	if parent.FrameworkID != item.FrameworkID {
+		return fmt.Errorf("%w: parent %q is in framework %q", ErrInvalidStandard, parentID, parent.FrameworkID)
+	}
+	if domain.StandardLevelRank(parent.Level) > domain.StandardLevelRank(item.Level) {
+		return fmt.Errorf("%w: a %s cannot be under a %s", ErrInvalidStandard, item.Level, parent.Level)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package standards
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func TestCheckParent(t *testing.T) {
+	// math6 holds 6.RP.A.1 with its ancestors 6.RP.A, 6.RP, MATH.
+	ancestorsOf := func(id string) []domain.StandardItem {
+		byID := make(map[string]domain.StandardItem)
+		for _, it := range math6 {
+			byID[it.ID] = it
+		}
+		var out []domain.StandardItem
+		for it, ok := byID[id]; ok; {
+			out = append(out, it)
+			if it.ParentID == nil {
+				break
+			}
+			it, ok = byID[*it.ParentID]
+		}
+		return out
+	}
+	tests := []struct {
+		name    string
+		item    domain.StandardItem
+		parent  string
+		wantErr bool
+	}{
+		{name: "standard under cluster", item: domain.StandardItem{ID: "6.RP.A.9", FrameworkID: "ccss", Level: domain.StandardLevelStandard}, parent: "6.RP.A"},
+		{name: "sub-standard under standard", item: domain.StandardItem{ID: "6.RP.A.1a", FrameworkID: "ccss", Level: domain.StandardLevelStandard}, parent: "6.RP.A.1"},
+		{name: "domain under cluster", item: domain.StandardItem{ID: "6.EE", FrameworkID: "ccss", Level: domain.StandardLevelDomain}, parent: "6.RP.A", wantErr: true},
+		{name: "other framework", item: domain.StandardItem{ID: "x", FrameworkID: "ngss", Level: domain.StandardLevelStandard}, parent: "6.RP.A", wantErr: true},
+		{name: "own parent", item: domain.StandardItem{ID: "6.RP.A", FrameworkID: "ccss", Level: domain.StandardLevelCluster}, parent: "6.RP.A", wantErr: true},
+		{name: "cycle", item: domain.StandardItem{ID: "6.RP", FrameworkID: "ccss", Level: domain.StandardLevelDomain}, parent: "6.RP.A", wantErr: true},
+		{name: "unknown parent", item: domain.StandardItem{ID: "6.RP.C", FrameworkID: "ccss", Level: domain.StandardLevelCluster}, parent: "6.ZZ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.item.ParentID = &tt.parent
+			err := checkParent(tt.item, ancestorsOf(tt.parent))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkParent() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidStandard) {
+				t.Errorf("err = %v, want ErrInvalidStandard", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package standards
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+// ImportCASE upserts a CASE package into the catalog in one transaction and reports what changed against the
+// framework's current contents. With dryRun nothing is written. Items the package no longer contains are
+// reported as missing but kept. A package whose standard IDs are already cataloged under another framework is rejected
+// with ErrOtherFramework, dry run or not.
+func (s *Service) ImportCASE(ctx context.Context, r io.Reader, dryRun bool) (*domain.CatalogImportReport, error) {
+	pkg, err := parseCASE(r)
+	if err != nil {
+		return nil, err
+	}
+	var report *domain.CatalogImportReport
+	err = s.uow.Do(ctx, func(tx pgx.Tx) error {
+		repo := s.repo.WithTx(tx)
+		ids := make([]string, len(pkg.Items))
+		for i, it := range pkg.Items {
+			ids[i] = it.ID
+		}
+		owners, err := repo.FrameworkIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		if err := checkOwners(pkg, owners); err != nil {
+			return err
+		}
+		existing, err := repo.GetFramework(ctx, pkg.Framework.ID)
+		if err != nil {
+			return err
+		}
+		items, err := repo.ListStandards(ctx, pkg.Framework.ID)
+		if err != nil {
+			return err
+		}
+		assocs, err := repo.ListAssociations(ctx, pkg.Framework.ID)
+		if err != nil {
+			return err
+		}
+		report = diffCatalog(existing, items, assocs, pkg)
+		report.DryRun = dryRun
+		if dryRun {
+			return nil
+		}
+
+		if err := repo.UpsertFramework(ctx, pkg.Framework); err != nil {
+			return err
+		}
+		changed := make(map[string]bool, len(report.Added)+len(report.Updated))
+		for _, id := range report.Added {
+			changed[id] = true
+		}
+		for _, c := range report.Updated {
+			changed[c.ID] = true
+		}
+		for _, item := range pkg.Items {
+			if !changed[item.ID] {
+				continue
+			}
+			if err := repo.UpsertStandard(ctx, item); err != nil {
+				return err
+			}
+		}
+		return repo.ReplaceAssociations(ctx, pkg.Framework.ID, pkg.Associations)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// checkOwners rejects a package if any of its standards is cataloged (owners maps standard to framework)
+// under a framework other than the package's.
+func checkOwners(pkg *catalogPackage, owners map[string]string) error {
+	var clashes []string
+	for _, it := range pkg.Items {
+		if fw, ok := owners[it.ID]; ok && fw != pkg.Framework.ID {
+			clashes = append(clashes, fmt.Sprintf("%s (in %s)", it.ID, fw))
+		}
+	}
+	if len(clashes) > 0 {
+		return fmt.Errorf("%w: %s", ErrOtherFramework, strings.Join(clashes, ", "))
+	}
+	return nil
+}
+
+// diffCatalog compares a parsed package with the cataloged framework (nil if new), its items and its associations.
+func diffCatalog(existing *domain.Framework, items []domain.StandardItem, assocs []domain.StandardAssociation, pkg *catalogPackage) *domain.CatalogImportReport {
+	report := &domain.CatalogImportReport{
+		FrameworkID:      pkg.Framework.ID,
+		Title:            pkg.Framework.Name,
+		Version:          pkg.Framework.Version,
+		FrameworkCreated: existing == nil,
+		Added:            []string{},
+		Updated:          []domain.StandardChange{},
+		Missing:          []string{},
+		Warnings:         pkg.Warnings,
+	}
+	if existing != nil {
+		report.PreviousVersion = existing.Version
+	}
+
+	current := make(map[string]domain.StandardItem, len(items))
+	for _, it := range items {
+		current[it.ID] = it
+	}
+	incoming := make(map[string]bool, len(pkg.Items))
+	for _, it := range pkg.Items {
+		incoming[it.ID] = true
+		old, ok := current[it.ID]
+		if !ok {
+			report.Added = append(report.Added, it.ID)
+			continue
+		}
+		if fields := changedFields(old, it); len(fields) > 0 {
+			report.Updated = append(report.Updated, domain.StandardChange{ID: it.ID, Fields: fields})
+		} else {
+			report.Unchanged++
+		}
+	}
+	for _, it := range items {
+		if !incoming[it.ID] {
+			report.Missing = append(report.Missing, it.ID)
+		}
+	}
+
+	currentAssocs := make(map[string]domain.StandardAssociation, len(assocs))
+	for _, a := range assocs {
+		currentAssocs[a.ID] = a
+	}
+	incomingAssocs := make(map[string]bool, len(pkg.Associations))
+	for _, a := range pkg.Associations {
+		incomingAssocs[a.ID] = true
+		switch old, ok := currentAssocs[a.ID]; {
+		case !ok:
+			report.AssociationsAdded++
+		case old != a:
+			report.AssociationsUpdated++
+		}
+	}
+	for _, a := range assocs {
+		if !incomingAssocs[a.ID] {
+			report.AssociationsRemoved++
+		}
+	}
+	return report
+}
+
+func changedFields(old, next domain.StandardItem) []string {
+	var fields []string
+	if ptrValue(old.ParentID) != ptrValue(next.ParentID) {
+		fields = append(fields, "parent_id")
+	}
+	if old.Level != next.Level {
+		fields = append(fields, "level")
+	}
+	if old.Code != next.Code {
+		fields = append(fields, "code")
+	}
+	if old.Description != next.Description {
+		fields = append(fields, "description")
+	}
+	if old.Position != next.Position {
+		fields = append(fields, "position")
+	}
+	return fields
+}
+
+func ptrValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
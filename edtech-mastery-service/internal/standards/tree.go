@@ -0,0 +1,98 @@
+package standards
+
+import "github.com/edtech-mastery/student-progress-service/internal/domain"
+
+// BuildFrameworkTree nests items under their parents, keeping the input order among siblings.
+// Items whose parent is missing from items become roots.
+func BuildFrameworkTree(items []domain.StandardItem) []*domain.StandardNode {
+	nodes := make(map[string]*domain.StandardNode, len(items))
+	for _, it := range items {
+		nodes[it.ID] = &domain.StandardNode{StandardItem: it}
+	}
+	var roots []*domain.StandardNode
+	for _, it := range items {
+		n := nodes[it.ID]
+		if it.ParentID != nil {
+			if parent, ok := nodes[*it.ParentID]; ok {
+				parent.Children = append(parent.Children, n)
+				continue
+			}
+		}
+		roots = append(roots, n)
+	}
+	return roots
+}
+
+// BuildMasteryTree rolls a student's mastery up the catalog: one root per framework, pruned to the nodes with
+// assessed standards below them. A node's score is the unweighted mean of its assessed children's scores,
+// with the node's own mastery (if it was assessed directly) counting as one more child. items must hold the
+// assessed standards and all their ancestors. Standards not in items are returned as uncataloged.
+func BuildMasteryTree(frameworks []domain.Framework, items []domain.StandardItem, mastery []domain.StandardMastery) (tree []*domain.MasteryNode, uncataloged []string) {
+	byStandard := make(map[string]domain.StandardMastery, len(mastery))
+	for _, m := range mastery {
+		byStandard[m.StandardID] = m
+	}
+	cataloged := make(map[string]bool, len(items))
+	children := make(map[string][]domain.StandardItem)
+	rootsByFramework := make(map[string][]domain.StandardItem)
+	for _, it := range items {
+		cataloged[it.ID] = true
+	}
+	for _, it := range items {
+		if it.ParentID != nil && cataloged[*it.ParentID] {
+			children[*it.ParentID] = append(children[*it.ParentID], it)
+		} else {
+			rootsByFramework[it.FrameworkID] = append(rootsByFramework[it.FrameworkID], it)
+		}
+	}
+
+	var build func(it domain.StandardItem) *domain.MasteryNode
+	build = func(it domain.StandardItem) *domain.MasteryNode {
+		n := &domain.MasteryNode{ID: it.ID, Level: it.Level, Code: it.Code, Description: it.Description}
+		var sum float64
+		var count int
+		for _, c := range children[it.ID] {
+			if cn := build(c); cn != nil {
+				n.Children = append(n.Children, cn)
+				sum += cn.MasteryScore
+				count++
+				n.StandardsAssessed += cn.StandardsAssessed
+			}
+		}
+		if m, ok := byStandard[it.ID]; ok {
+			sum += m.MasteryScore
+			count++
+			n.StandardsAssessed++
+			if len(n.Children) == 0 {
+				n.Band = m.Band
+			}
+		}
+		if count == 0 {
+			return nil
+		}
+		n.MasteryScore = sum / float64(count)
+		return n
+	}
+
+	for _, f := range frameworks {
+		root := &domain.MasteryNode{ID: f.ID, Level: domain.MasteryLevelFramework, Description: f.Name}
+		var sum float64
+		for _, it := range rootsByFramework[f.ID] {
+			if n := build(it); n != nil {
+				root.Children = append(root.Children, n)
+				sum += n.MasteryScore
+				root.StandardsAssessed += n.StandardsAssessed
+			}
+		}
+		if len(root.Children) > 0 {
+			root.MasteryScore = sum / float64(len(root.Children))
+			tree = append(tree, root)
+		}
+	}
+	for _, m := range mastery {
+		if !cataloged[m.StandardID] {
+			uncataloged = append(uncataloged, m.StandardID)
+		}
+	}
+	return tree, uncataloged
+}
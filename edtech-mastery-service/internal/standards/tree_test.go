@@ -0,0 +1,106 @@
+package standards
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+func ptrString(s string) *string { return &s }
+
+// math6 is a slice of a CCSS-like framework:
+//
+//	MATH > 6.RP > 6.RP.A > 6.RP.A.1, 6.RP.A.2
+//	            > 6.RP.B > 6.RP.B.3
+//	     > 6.NS > 6.NS.A > 6.NS.A.1
+var math6 = []domain.StandardItem{
+	{ID: "MATH", FrameworkID: "ccss", Level: domain.StandardLevelSubject},
+	{ID: "6.RP", FrameworkID: "ccss", ParentID: ptrString("MATH"), Level: domain.StandardLevelDomain},
+	{ID: "6.RP.A", FrameworkID: "ccss", ParentID: ptrString("6.RP"), Level: domain.StandardLevelCluster},
+	{ID: "6.RP.A.1", FrameworkID: "ccss", ParentID: ptrString("6.RP.A"), Level: domain.StandardLevelStandard},
+	{ID: "6.RP.A.2", FrameworkID: "ccss", ParentID: ptrString("6.RP.A"), Level: domain.StandardLevelStandard},
+	{ID: "6.RP.B", FrameworkID: "ccss", ParentID: ptrString("6.RP"), Level: domain.StandardLevelCluster},
+	{ID: "6.RP.B.3", FrameworkID: "ccss", ParentID: ptrString("6.RP.B"), Level: domain.StandardLevelStandard},
+	{ID: "6.NS", FrameworkID: "ccss", ParentID: ptrString("MATH"), Level: domain.StandardLevelDomain},
+	{ID: "6.NS.A", FrameworkID: "ccss", ParentID: ptrString("6.NS"), Level: domain.StandardLevelCluster},
+	{ID: "6.NS.A.1", FrameworkID: "ccss", ParentID: ptrString("6.NS.A"), Level: domain.StandardLevelStandard},
+}
+
+func TestBuildFrameworkTree(t *testing.T) {
+	roots := BuildFrameworkTree(math6)
+	if len(roots) != 1 || roots[0].ID != "MATH" {
+		t.Fatalf("roots = %v, want [MATH]", roots)
+	}
+	var domains []string
+	for _, c := range roots[0].Children {
+		domains = append(domains, c.ID)
+	}
+	if !reflect.DeepEqual(domains, []string{"6.RP", "6.NS"}) {
+		t.Errorf("domains = %v, want [6.RP 6.NS]", domains)
+	}
+	if n := len(roots[0].Children[0].Children[0].Children); n != 2 {
+		t.Errorf("6.RP.A has %d standards, want 2", n)
+	}
+}
+
+func TestBuildMasteryTree(t *testing.T) {
+	mastery := []domain.StandardMastery{
+		{StandardID: "6.RP.A.1", MasteryScore: 0.8, Band: domain.BandProficient},
+		{StandardID: "6.RP.A.2", MasteryScore: 0.6, Band: domain.BandDeveloping},
+		{StandardID: "6.RP.B.3", MasteryScore: 1.0, Band: domain.BandAdvanced},
+		{StandardID: "std-legacy", MasteryScore: 0.5},
+	}
+	frameworks := []domain.Framework{{ID: "ccss", Name: "CCSS Math"}, {ID: "ngss", Name: "NGSS"}}
+	tree, uncataloged := BuildMasteryTree(frameworks, math6, mastery)
+
+	if !reflect.DeepEqual(uncataloged, []string{"std-legacy"}) {
+		t.Errorf("uncataloged = %v, want [std-legacy]", uncataloged)
+	}
+	if len(tree) != 1 || tree[0].ID != "ccss" || tree[0].Level != domain.MasteryLevelFramework {
+		t.Fatalf("tree roots = %v, want the ccss framework only", tree)
+	}
+	subject := tree[0].Children[0]
+	if len(subject.Children) != 1 || subject.Children[0].ID != "6.RP" {
+		t.Fatalf("subject children = %v, want only 6.RP (6.NS has no evidence)", subject.Children)
+	}
+	rp := subject.Children[0]
+	clusterA, clusterB := rp.Children[0], rp.Children[1]
+
+	checks := []struct {
+		node     *domain.MasteryNode
+		score    float64
+		assessed int
+	}{
+		{clusterA, 0.7, 2},
+		{clusterB, 1.0, 1},
+		{rp, 0.85, 3}, // mean of clusters, not of standards
+		{subject, 0.85, 3},
+		{tree[0], 0.85, 3},
+	}
+	for _, c := range checks {
+		if math.Abs(c.node.MasteryScore-c.score) > 1e-9 || c.node.StandardsAssessed != c.assessed {
+			t.Errorf("%s = (%v, %d), want (%v, %d)", c.node.ID, c.node.MasteryScore, c.node.StandardsAssessed, c.score, c.assessed)
+		}
+	}
+	if clusterA.Children[0].Band != domain.BandProficient || clusterA.Band != "" {
+		t.Errorf("bands: leaf %q, cluster %q; want leaf proficient and no band on rollups", clusterA.Children[0].Band, clusterA.Band)
+	}
+}
+
+func TestBuildMasteryTreeDirectlyAssessedParent(t *testing.T) {
+	items := []domain.StandardItem{
+		{ID: "6.RP.A.3", FrameworkID: "ccss", Level: domain.StandardLevelStandard},
+		{ID: "6.RP.A.3a", FrameworkID: "ccss", ParentID: ptrString("6.RP.A.3"), Level: domain.StandardLevelStandard},
+	}
+	mastery := []domain.StandardMastery{
+		{StandardID: "6.RP.A.3", MasteryScore: 0.4},
+		{StandardID: "6.RP.A.3a", MasteryScore: 1.0},
+	}
+	tree, _ := BuildMasteryTree([]domain.Framework{{ID: "ccss"}}, items, mastery)
+	parent := tree[0].Children[0]
+	if math.Abs(parent.MasteryScore-0.7) > 1e-9 || parent.StandardsAssessed != 2 {
+		t.Errorf("6.RP.A.3 = (%v, %d), want (0.7, 2)", parent.MasteryScore, parent.StandardsAssessed)
+	}
+}
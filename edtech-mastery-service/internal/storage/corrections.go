@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+type CorrectionsRepo struct {
+	db DBTX
+}
+
+func NewCorrectionsRepo(pool *pgxpool.Pool) *CorrectionsRepo {
+	return &CorrectionsRepo{db: pool}
+}
+
+// WithTx returns a copy of the repo that runs its statements inside tx.
+func (r *CorrectionsRepo) WithTx(tx pgx.Tx) *CorrectionsRepo {
+	return &CorrectionsRepo{db: tx}
+}
+
+// CorrectionState is what the applied corrections have done to an event so far.
+type CorrectionState struct {
+	Retracted bool
+	// LatestUpdate is the GRADE_UPDATED event in effect (latest by timestamp), or 0.
+	LatestUpdate int64
+}
+
+// LockOriginal returns the referenced event with its outbox status, locked until the end of the transaction so
+// corrections of one event apply one at a time. It returns nil if there is no such event.
+func (r *CorrectionsRepo) LockOriginal(ctx context.Context, source, eventID string) (*domain.Event, string, error) {
+	var (
+		e      domain.Event
+		status string
+	)
+	err := r.db.QueryRow(ctx,
+		`SELECT e.id, e.event_id, e.source, e.type, e.payload, e.occurred_at, e.created_at, COALESCE(o.status, '')
+		 FROM events e
+		 LEFT JOIN event_outbox o ON o.event_db_id = e.id
+		 WHERE e.source = $1 AND e.event_id = $2
+		 FOR UPDATE OF e`,
+		source, eventID,
+	).Scan(&e.ID, &e.EventID, &e.Source, &e.Type, &e.Payload, &e.OccurredAt, &e.CreatedAt, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return &e, status, nil
+}
+
+func (r *CorrectionsRepo) State(ctx context.Context, originalEventDBID int64) (CorrectionState, error) {
+	var (
+		s      CorrectionState
+		latest *int64
+	)
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM event_corrections WHERE original_event_db_id = $1 AND kind = 'EVENT_RETRACTED'),
+		        (SELECT event_db_id FROM event_corrections WHERE original_event_db_id = $1 AND kind = 'GRADE_UPDATED'
+		         ORDER BY occurred_at DESC, event_db_id DESC LIMIT 1)`,
+		originalEventDBID,
+	).Scan(&s.Retracted, &latest)
+	if latest != nil {
+		s.LatestUpdate = *latest
+	}
+	return s, err
+}
+
+// Insert records a correction. It reports false if it was already recorded, in which case the caller must not
+// apply it again.
+func (r *CorrectionsRepo) Insert(ctx context.Context, c domain.EventCorrection) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`INSERT INTO event_corrections (event_db_id, original_event_db_id, kind, normalized_score, occurred_at)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (event_db_id) DO NOTHING`,
+		c.EventDBID, c.OriginalEventDBID, c.Kind, c.NormalizedScore, c.OccurredAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -12,6 +13,9 @@ import (
 
 var ErrDuplicateEvent = errors.New("duplicate event (source, event_id)")
 
+// OutboxNotifyChannel is the channel NOTIFYed when new outbox rows are committed, so idle workers wake up.
+const OutboxNotifyChannel = "event_outbox"
+
 type EventRepo struct {
 	pool *pgxpool.Pool
 }
@@ -20,24 +24,100 @@ func NewEventRepo(pool *pgxpool.Pool) *EventRepo {
 	return &EventRepo{pool: pool}
 }
 
+// NewEvent is a validated event ready to be appended to the events table.
+// StudentID, ClassID, AssignmentID and OccurredAt are copied out of the payload into indexed columns.
+type NewEvent struct {
+	EventID         string
+	Source          string
+	Type            string
+	StudentID       string
+	ClassID         string
+	AssignmentID    string
+	OccurredAt      time.Time
+	NormalizedScore *float64
+	Payload         []byte
+}
+
+// InsertResult is the outcome of storing one NewEvent. Duplicate is set when (source, event_id) already existed; ID is then the existing row.
+type InsertResult struct {
+	ID        int64
+	Duplicate bool
+	// Err is set by InsertEvents when this event alone could not be stored.
+	Err error
+}
+
 // InsertEvent inserts into events and outbox in one transaction. Idempotent: duplicate (source, event_id) does not create a new outbox row.
-func (r *EventRepo) InsertEvent(ctx context.Context, eventID, source, eventType string, payload []byte) (eventDBID int64, err error) {
+func (r *EventRepo) InsertEvent(ctx context.Context, ev NewEvent) (eventDBID int64, err error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return 0, err
 	}
 	defer tx.Rollback(ctx)
 
+	res, err := insertEvent(ctx, tx, ev)
+	if err != nil {
+		return 0, err
+	}
+	if !res.Duplicate {
+		if err = notifyOutbox(ctx, tx); err != nil {
+			return 0, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return res.ID, nil
+}
+
+// InsertEvents stores a batch of events and their outbox rows in a single transaction.
+// Results are returned in input order; a duplicate within the batch itself is reported as a duplicate of the earlier item.
+// Each event is inserted under a savepoint, so one the database rejects gets its own Err and the others are kept;
+// the returned error means the whole transaction failed.
+func (r *EventRepo) InsertEvents(ctx context.Context, evs []NewEvent) ([]InsertResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	out := make([]InsertResult, 0, len(evs))
+	enqueued := false
+	for _, ev := range evs {
+		var res InsertResult
+		err := Savepoint(ctx, tx, func(sp pgx.Tx) error {
+			var err error
+			res, err = insertEvent(ctx, sp, ev)
+			return err
+		})
+		if err != nil {
+			out = append(out, InsertResult{Err: err})
+			continue
+		}
+		enqueued = enqueued || !res.Duplicate
+		out = append(out, res)
+	}
+	if enqueued {
+		if err = notifyOutbox(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func insertEvent(ctx context.Context, tx pgx.Tx, ev NewEvent) (InsertResult, error) {
 	var id int64
-	err = tx.QueryRow(ctx,
-		`INSERT INTO events (event_id, source, type, payload, created_at)
-		 VALUES ($1, $2, $3, $4, NOW())
+	err := tx.QueryRow(ctx,
+		`INSERT INTO events (event_id, source, type, student_id, class_id, assignment_id, occurred_at, normalized_score, payload, created_at)
+		 VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, NOW())
 		 ON CONFLICT (source, event_id) DO NOTHING
 		 RETURNING id`,
-		eventID, source, eventType, payload,
+		ev.EventID, ev.Source, ev.Type, ev.StudentID, ev.ClassID, ev.AssignmentID, ev.OccurredAt, ev.NormalizedScore, ev.Payload,
 	).Scan(&id)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-		return 0, err
+		return InsertResult{}, err
 	}
 	if err == nil {
 		// New row inserted; enqueue for worker
@@ -46,34 +126,34 @@ func (r *EventRepo) InsertEvent(ctx context.Context, eventID, source, eventType
 			id,
 		)
 		if err != nil {
-			return 0, err
+			return InsertResult{}, err
 		}
-		if err = tx.Commit(ctx); err != nil {
-			return 0, err
-		}
-		return id, nil
+		return InsertResult{ID: id}, nil
 	}
 
 	// Duplicate: get existing id
 	err = tx.QueryRow(ctx,
 		`SELECT id FROM events WHERE source = $1 AND event_id = $2`,
-		source, eventID,
+		ev.Source, ev.EventID,
 	).Scan(&id)
 	if err != nil {
-		return 0, err
+		return InsertResult{}, err
 	}
-	if err = tx.Commit(ctx); err != nil {
-		return 0, err
-	}
-	return id, nil
+	return InsertResult{ID: id, Duplicate: true}, nil
+}
+
+// notifyOutbox queues a NOTIFY that Postgres delivers only when tx commits.
+func notifyOutbox(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, `SELECT pg_notify($1, '')`, OutboxNotifyChannel)
+	return err
 }
 
 func (r *EventRepo) GetEventByID(ctx context.Context, id int64) (*domain.Event, error) {
 	var e domain.Event
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, event_id, source, type, payload, created_at FROM events WHERE id = $1`,
+		`SELECT id, event_id, source, type, payload, occurred_at, created_at FROM events WHERE id = $1`,
 		id,
-	).Scan(&e.ID, &e.EventID, &e.Source, &e.Type, &e.Payload, &e.CreatedAt)
+	).Scan(&e.ID, &e.EventID, &e.Source, &e.Type, &e.Payload, &e.OccurredAt, &e.CreatedAt)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// The benchmarks compare the old JSONB-filtered reads with the promoted, indexed columns.
+// They need a migrated database and only run when BENCH_DATABASE_URL is set:
+//
+//	BENCH_DATABASE_URL=postgres://... go test -run '^$' -bench . ./internal/storage
+//
+// Seed rows are written inside a transaction that is rolled back afterwards.
+
+const (
+	benchClasses          = 200
+	benchStudentsPerClass = 30
+	benchEventsPerStudent = 20
+	benchClassID          = "bench-class-100"
+	benchStudentID        = "bench-student-100-7"
+)
+
+func benchTx(b *testing.B) pgx.Tx {
+	b.Helper()
+	dsn := os.Getenv("BENCH_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("BENCH_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(pool.Close)
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { _ = tx.Rollback(ctx) })
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO events (event_id, source, type, student_id, class_id, assignment_id, payload, occurred_at)
+		SELECT 'bench-' || c || '-' || s || '-' || n, 'bench',
+		       CASE WHEN n % 2 = 0 THEN 'ASSIGNMENT_ASSIGNED' ELSE 'SUBMISSION_GRADED' END,
+		       'bench-student-' || c || '-' || s, 'bench-class-' || c, 'assign-' || (n / 2),
+		       jsonb_build_object(
+		         'student_id', 'bench-student-' || c || '-' || s,
+		         'class_id', 'bench-class-' || c,
+		         'assignment_id', 'assign-' || (n / 2),
+		         'score', CASE WHEN n % 2 = 1 THEN 60 + (n * 7 + s) % 40 END),
+		       NOW() - make_interval(mins => n)
+		FROM generate_series(1, $1) c, generate_series(1, $2) s, generate_series(1, $3) n`,
+		benchClasses, benchStudentsPerClass, benchEventsPerStudent,
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if _, err := tx.Exec(ctx, `ANALYZE events`); err != nil {
+		b.Fatal(err)
+	}
+	return tx
+}
+
+func runBenchQuery(b *testing.B, tx pgx.Tx, sql string, args ...any) {
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for rows.Next() {
+		}
+		if err := rows.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTimelineQuery(b *testing.B) {
+	tx := benchTx(b)
+	b.Run("jsonb", func(b *testing.B) {
+		runBenchQuery(b, tx, `
+			SELECT e.type, (e.payload->>'assignment_id')::text, (e.payload->>'score')::float, e.created_at
+			FROM events e
+			WHERE e.payload->>'student_id' = $1 AND e.payload->>'class_id' = $2
+			ORDER BY e.created_at DESC LIMIT 50`, benchStudentID, benchClassID)
+	})
+	b.Run("column", func(b *testing.B) {
+		runBenchQuery(b, tx, `
+			SELECT e.type, e.assignment_id, (e.payload->>'score')::float, e.occurred_at
+			FROM events e
+			WHERE e.student_id = $1 AND e.class_id = $2
+			ORDER BY e.occurred_at DESC, e.id DESC LIMIT 50`, benchStudentID, benchClassID)
+	})
+}
+
+func BenchmarkRecentActivityQuery(b *testing.B) {
+	tx := benchTx(b)
+	b.Run("jsonb", func(b *testing.B) {
+		runBenchQuery(b, tx, `
+			SELECT e.type, e.payload->>'student_id', e.payload->>'assignment_id', e.created_at
+			FROM events e
+			WHERE e.payload->>'class_id' = $1
+			ORDER BY e.created_at DESC LIMIT 20`, benchClassID)
+	})
+	b.Run("column", func(b *testing.B) {
+		runBenchQuery(b, tx, `
+			SELECT e.type, e.student_id, e.assignment_id, e.occurred_at
+			FROM events e
+			WHERE e.class_id = $1
+			ORDER BY e.occurred_at DESC, e.id DESC LIMIT 20`, benchClassID)
+	})
+}
+
+func BenchmarkClassGradedScan(b *testing.B) {
+	tx := benchTx(b)
+	b.Run("jsonb", func(b *testing.B) {
+		runBenchQuery(b, tx, `
+			SELECT DISTINCT payload->>'student_id' FROM events
+			WHERE type = 'SUBMISSION_GRADED' AND payload->>'class_id' = $1`, benchClassID)
+	})
+	b.Run("column", func(b *testing.B) {
+		runBenchQuery(b, tx, `
+			SELECT DISTINCT student_id FROM events
+			WHERE type = 'SUBMISSION_GRADED' AND class_id = $1`, benchClassID)
+	})
+}
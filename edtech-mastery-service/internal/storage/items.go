@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+type ItemsRepo struct {
+	db DBTX
+}
+
+func NewItemsRepo(pool *pgxpool.Pool) *ItemsRepo {
+	return &ItemsRepo{db: pool}
+}
+
+// WithTx returns a copy of the repo that runs its statements inside tx.
+func (r *ItemsRepo) WithTx(tx pgx.Tx) *ItemsRepo {
+	return &ItemsRepo{db: tx}
+}
+
+// InsertResponse records an item response once per event.
+func (r *ItemsRepo) InsertResponse(ctx context.Context, resp domain.ItemResponse) error {
+	if resp.StandardIDs == nil {
+		resp.StandardIDs = []string{}
+	}
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO item_responses (event_db_id, student_id, class_id, assignment_id, item_id, standard_ids,
+		                             credit, response_time_ms, occurred_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 ON CONFLICT (event_db_id) DO NOTHING`,
+		resp.EventDBID, resp.StudentID, resp.ClassID, resp.AssignmentID, resp.ItemID, resp.StandardIDs,
+		resp.Credit, resp.ResponseTimeMs, resp.OccurredAt,
+	)
+	return err
+}
+
+// LatestResponses returns each student's latest response to each item of the assignment, optionally in one class.
+func (r *ItemsRepo) LatestResponses(ctx context.Context, assignmentID, classID string) ([]domain.ItemResponse, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT DISTINCT ON (student_id, item_id)
+		        event_db_id, student_id, class_id, item_id, standard_ids, credit, response_time_ms, occurred_at
+		 FROM item_responses
+		 WHERE assignment_id = $1 AND ($2 = '' OR class_id = $2)
+		 ORDER BY student_id, item_id, occurred_at DESC, event_db_id DESC`,
+		assignmentID, classID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.ItemResponse
+	for rows.Next() {
+		resp := domain.ItemResponse{AssignmentID: assignmentID}
+		if err := rows.Scan(&resp.EventDBID, &resp.StudentID, &resp.ClassID, &resp.ItemID, &resp.StandardIDs,
+			&resp.Credit, &resp.ResponseTimeMs, &resp.OccurredAt); err != nil {
+			return nil, err
+		}
+		out = append(out, resp)
+	}
+	return out, rows.Err()
+}
+
+// DeleteResponse removes a retracted event's response from item analysis.
+func (r *ItemsRepo) DeleteResponse(ctx context.Context, eventDBID int64) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM item_responses WHERE event_db_id = $1`, eventDBID)
+	return err
+}
@@ -2,33 +2,189 @@ package storage
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/edtech-mastery/student-progress-service/internal/domain"
 )
 
 type MasteryRepo struct {
-	pool *pgxpool.Pool
+	db DBTX
 }
 
 func NewMasteryRepo(pool *pgxpool.Pool) *MasteryRepo {
-	return &MasteryRepo{pool: pool}
+	return &MasteryRepo{db: pool}
 }
 
-func (r *MasteryRepo) UpsertMastery(ctx context.Context, studentID, standardID string, score float64) error {
-	_, err := r.pool.Exec(ctx,
-		`INSERT INTO student_mastery (student_id, standard_id, mastery_score, updated_at)
-		 VALUES ($1, $2, $3, NOW())
-		 ON CONFLICT (student_id, standard_id) DO UPDATE SET mastery_score = $3, updated_at = NOW()`,
-		studentID, standardID, score,
+// WithTx returns a copy of the repo that runs its statements inside tx.
+func (r *MasteryRepo) WithTx(tx pgx.Tx) *MasteryRepo {
+	return &MasteryRepo{db: tx}
+}
+
+// LockMastery returns the student's state for the standard, locked for update, or nil if there is none yet.
+func (r *MasteryRepo) LockMastery(ctx context.Context, studentID, standardID string) (*domain.MasteryState, error) {
+	s := domain.MasteryState{StudentID: studentID, StandardID: standardID}
+	err := r.db.QueryRow(ctx,
+		`SELECT model, mastery_score, evidence_weight, evidence_count, last_evidence_at, band
+		 FROM student_mastery WHERE student_id = $1 AND standard_id = $2
+		 FOR UPDATE`,
+		studentID, standardID,
+	).Scan(&s.Model, &s.Score, &s.EvidenceWeight, &s.EvidenceCount, &s.LastEvidenceAt, &s.Band)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *MasteryRepo) SaveMastery(ctx context.Context, s domain.MasteryState) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO student_mastery (student_id, standard_id, mastery_score, model, evidence_weight, evidence_count, last_evidence_at, band, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		 ON CONFLICT (student_id, standard_id) DO UPDATE SET
+		   mastery_score = $3, model = $4, evidence_weight = $5, evidence_count = $6, last_evidence_at = $7, band = $8, updated_at = NOW()`,
+		s.StudentID, s.StandardID, s.Score, s.Model, s.EvidenceWeight, s.EvidenceCount, s.LastEvidenceAt, s.Band,
+	)
+	return err
+}
+
+// GetMastery returns the student's current mastery of the standard, or nil if there is no evidence yet.
+func (r *MasteryRepo) GetMastery(ctx context.Context, studentID, standardID string) (*domain.StandardMastery, error) {
+	s := domain.StandardMastery{StandardID: standardID}
+	err := r.db.QueryRow(ctx,
+		`SELECT mastery_score, band, model, evidence_count, last_evidence_at
+		 FROM student_mastery WHERE student_id = $1 AND standard_id = $2`,
+		studentID, standardID,
+	).Scan(&s.MasteryScore, &s.Band, &s.Model, &s.EvidenceCount, &s.LastEvidenceAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// InsertEvidence records the event's contribution to the standard. It reports false if the event
+// was already recorded for the standard, in which case the caller must not apply it again.
+func (r *MasteryRepo) InsertEvidence(ctx context.Context, e domain.MasteryEvidence) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`INSERT INTO mastery_evidence (student_id, standard_id, event_db_id, class_id, assignment_id, item_id, model,
+		                               raw_score, grade, scale, points_possible, evidence_score, criteria, weight,
+		                               mastery_before, mastery_after, occurred_at)
+		 VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, $12, $13, $14, $15, $16, $17)
+		 ON CONFLICT (event_db_id, standard_id) DO NOTHING`,
+		e.StudentID, e.StandardID, e.EventDBID, e.ClassID, e.AssignmentID, e.ItemID, e.Model,
+		e.RawScore, e.Grade, e.Scale, e.PointsPossible, e.EvidenceScore, e.Criteria, e.Weight,
+		e.MasteryBefore, e.MasteryAfter, e.OccurredAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// VoidEvidence marks the evidence an event contributed, directly or through earlier corrections of it, as voided by
+// the correction voidedBy. It returns the standards that lost evidence.
+func (r *MasteryRepo) VoidEvidence(ctx context.Context, studentID string, originalEventDBID, voidedBy int64) ([]string, error) {
+	rows, err := r.db.Query(ctx,
+		`UPDATE mastery_evidence SET voided_by = $3
+		 WHERE student_id = $1 AND voided_by IS NULL
+		   AND (event_db_id = $2 OR event_db_id IN (SELECT event_db_id FROM event_corrections WHERE original_event_db_id = $2))
+		 RETURNING standard_id`,
+		studentID, originalEventDBID, voidedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var std string
+		if err := rows.Scan(&std); err != nil {
+			return nil, err
+		}
+		out = append(out, std)
+	}
+	return out, rows.Err()
+}
+
+// ActiveEvidence returns the standard's evidence that has not been voided, in the order it happened.
+func (r *MasteryRepo) ActiveEvidence(ctx context.Context, studentID, standardID string) ([]domain.MasteryEvidence, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, event_db_id, evidence_score, weight, occurred_at
+		 FROM mastery_evidence
+		 WHERE student_id = $1 AND standard_id = $2 AND voided_by IS NULL
+		 ORDER BY occurred_at, id`,
+		studentID, standardID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.MasteryEvidence
+	for rows.Next() {
+		e := domain.MasteryEvidence{StudentID: studentID, StandardID: standardID}
+		if err := rows.Scan(&e.ID, &e.EventDBID, &e.EvidenceScore, &e.Weight, &e.OccurredAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// DeleteMastery removes a student's mastery of a standard that has no evidence left.
+func (r *MasteryRepo) DeleteMastery(ctx context.Context, studentID, standardID string) error {
+	_, err := r.db.Exec(ctx,
+		`DELETE FROM student_mastery WHERE student_id = $1 AND standard_id = $2`,
+		studentID, standardID,
 	)
 	return err
 }
 
+// ListEvidence returns the standard's evidence in the order it was applied, after afterID.
+func (r *MasteryRepo) ListEvidence(ctx context.Context, studentID, standardID string, afterID int64, limit int) ([]domain.MasteryEvidence, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+	rows, err := r.db.Query(ctx,
+		`SELECT id, event_db_id, class_id, COALESCE(assignment_id, ''), COALESCE(item_id, ''), model, raw_score, COALESCE(grade, ''),
+		        COALESCE(scale, ''), points_possible, evidence_score, criteria, weight, mastery_before, mastery_after, occurred_at, recorded_at,
+		        voided_by
+		 FROM mastery_evidence
+		 WHERE student_id = $1 AND standard_id = $2 AND id > $3
+		 ORDER BY id
+		 LIMIT $4`,
+		studentID, standardID, afterID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.MasteryEvidence{}
+	for rows.Next() {
+		e := domain.MasteryEvidence{StudentID: studentID, StandardID: standardID}
+		if err := rows.Scan(&e.ID, &e.EventDBID, &e.ClassID, &e.AssignmentID, &e.ItemID, &e.Model, &e.RawScore, &e.Grade,
+			&e.Scale, &e.PointsPossible, &e.EvidenceScore, &e.Criteria, &e.Weight, &e.MasteryBefore, &e.MasteryAfter, &e.OccurredAt, &e.RecordedAt,
+			&e.VoidedBy); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
 func (r *MasteryRepo) GetMasteryByStudent(ctx context.Context, studentID string) ([]domain.StandardMastery, error) {
-	rows, err := r.pool.Query(ctx,
-		`SELECT standard_id, mastery_score FROM student_mastery WHERE student_id = $1 ORDER BY standard_id`,
+	rows, err := r.db.Query(ctx,
+		`SELECT standard_id, mastery_score, band, model, evidence_count, last_evidence_at
+		 FROM student_mastery WHERE student_id = $1 ORDER BY standard_id`,
 		studentID,
 	)
 	if err != nil {
@@ -39,7 +195,66 @@ func (r *MasteryRepo) GetMasteryByStudent(ctx context.Context, studentID string)
 	var out []domain.StandardMastery
 	for rows.Next() {
 		var s domain.StandardMastery
-		if err := rows.Scan(&s.StandardID, &s.MasteryScore); err != nil {
+		if err := rows.Scan(&s.StandardID, &s.MasteryScore, &s.Band, &s.Model, &s.EvidenceCount, &s.LastEvidenceAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// ModelSettingsFor returns the class's own setting (if any) and every framework setting.
+func (r *MasteryRepo) ModelSettingsFor(ctx context.Context, classID string) ([]domain.MasteryModelSetting, error) {
+	return r.queryModelSettings(ctx,
+		`SELECT scope, scope_id, model, params, updated_at FROM mastery_model_settings
+		 WHERE (scope = 'class' AND scope_id = $1) OR scope = 'framework'`,
+		classID,
+	)
+}
+
+func (r *MasteryRepo) ListModelSettings(ctx context.Context) ([]domain.MasteryModelSetting, error) {
+	return r.queryModelSettings(ctx,
+		`SELECT scope, scope_id, model, params, updated_at FROM mastery_model_settings ORDER BY scope, scope_id`,
+	)
+}
+
+func (r *MasteryRepo) UpsertModelSetting(ctx context.Context, s domain.MasteryModelSetting) error {
+	params := s.Params
+	if len(params) == 0 {
+		params = []byte("{}")
+	}
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO mastery_model_settings (scope, scope_id, model, params, updated_at)
+		 VALUES ($1, $2, $3, $4, NOW())
+		 ON CONFLICT (scope, scope_id) DO UPDATE SET model = $3, params = $4, updated_at = NOW()`,
+		s.Scope, s.ScopeID, s.Model, params,
+	)
+	return err
+}
+
+// DeleteModelSetting reports false if there was no setting for the scope.
+func (r *MasteryRepo) DeleteModelSetting(ctx context.Context, scope, scopeID string) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM mastery_model_settings WHERE scope = $1 AND scope_id = $2`,
+		scope, scopeID,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *MasteryRepo) queryModelSettings(ctx context.Context, sql string, args ...interface{}) ([]domain.MasteryModelSetting, error) {
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.MasteryModelSetting
+	for rows.Next() {
+		var s domain.MasteryModelSetting
+		if err := rows.Scan(&s.Scope, &s.ScopeID, &s.Model, &s.Params, &s.UpdatedAt); err != nil {
 			return nil, err
 		}
 		out = append(out, s)
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+// BandTransitionQuery filters a class's band transitions. A rank of -1 disables that filter.
+type BandTransitionQuery struct {
+	ClassID string
+	Since   time.Time
+	// DroppedBelowRank keeps transitions from at or above the rank to below it.
+	DroppedBelowRank int
+	// ReachedRank keeps transitions from below the rank to at or above it.
+	ReachedRank int
+	Limit       int
+}
+
+// BandSettingsFor returns the class's district (empty if unassigned), that district's band setting (if any)
+// and every framework band setting.
+func (r *MasteryRepo) BandSettingsFor(ctx context.Context, classID string) (string, []domain.MasteryBandSetting, error) {
+	var districtID string
+	err := r.db.QueryRow(ctx, `SELECT district_id FROM class_districts WHERE class_id = $1`, classID).Scan(&districtID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, err
+	}
+	settings, err := r.queryBandSettings(ctx,
+		`SELECT scope, scope_id, developing, proficient, advanced, updated_at FROM mastery_band_settings
+		 WHERE (scope = 'district' AND scope_id = $1) OR scope = 'framework'`,
+		districtID,
+	)
+	return districtID, settings, err
+}
+
+func (r *MasteryRepo) ListBandSettings(ctx context.Context) ([]domain.MasteryBandSetting, error) {
+	return r.queryBandSettings(ctx,
+		`SELECT scope, scope_id, developing, proficient, advanced, updated_at FROM mastery_band_settings ORDER BY scope, scope_id`,
+	)
+}
+
+func (r *MasteryRepo) UpsertBandSetting(ctx context.Context, s domain.MasteryBandSetting) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO mastery_band_settings (scope, scope_id, developing, proficient, advanced, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, NOW())
+		 ON CONFLICT (scope, scope_id) DO UPDATE SET developing = $3, proficient = $4, advanced = $5, updated_at = NOW()`,
+		s.Scope, s.ScopeID, s.Developing, s.Proficient, s.Advanced,
+	)
+	return err
+}
+
+// DeleteBandSetting reports false if there was no setting for the scope.
+func (r *MasteryRepo) DeleteBandSetting(ctx context.Context, scope, scopeID string) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM mastery_band_settings WHERE scope = $1 AND scope_id = $2`,
+		scope, scopeID,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *MasteryRepo) SetClassDistrict(ctx context.Context, classID, districtID string) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO class_districts (class_id, district_id, updated_at)
+		 VALUES ($1, $2, NOW())
+		 ON CONFLICT (class_id) DO UPDATE SET district_id = $2, updated_at = NOW()`,
+		classID, districtID,
+	)
+	return err
+}
+
+func (r *MasteryRepo) InsertBandTransition(ctx context.Context, t domain.BandTransition) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO mastery_band_transitions (student_id, standard_id, class_id, event_db_id, from_band, to_band,
+		                                       from_rank, to_rank, mastery_before, mastery_after, occurred_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		 ON CONFLICT (event_db_id, standard_id) DO NOTHING`,
+		t.StudentID, t.StandardID, t.ClassID, t.EventDBID, t.FromBand, t.ToBand,
+		domain.BandRank(t.FromBand), domain.BandRank(t.ToBand), t.MasteryBefore, t.MasteryAfter, t.OccurredAt,
+	)
+	return err
+}
+
+// ListBandTransitions returns the class's transitions since q.Since, newest first.
+func (r *MasteryRepo) ListBandTransitions(ctx context.Context, q BandTransitionQuery) ([]domain.BandTransition, error) {
+	if q.Limit <= 0 || q.Limit > 1000 {
+		q.Limit = 200
+	}
+	rows, err := r.db.Query(ctx,
+		`SELECT id, student_id, standard_id, class_id, event_db_id, from_band, to_band,
+		        mastery_before, mastery_after, occurred_at, recorded_at
+		 FROM mastery_band_transitions
+		 WHERE class_id = $1 AND occurred_at >= $2
+		   AND ($3 < 0 OR (from_rank >= $3 AND to_rank < $3))
+		   AND ($4 < 0 OR (from_rank < $4 AND to_rank >= $4))
+		 ORDER BY occurred_at DESC, id DESC
+		 LIMIT $5`,
+		q.ClassID, q.Since, q.DroppedBelowRank, q.ReachedRank, q.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.BandTransition{}
+	for rows.Next() {
+		var t domain.BandTransition
+		if err := rows.Scan(&t.ID, &t.StudentID, &t.StandardID, &t.ClassID, &t.EventDBID, &t.FromBand, &t.ToBand,
+			&t.MasteryBefore, &t.MasteryAfter, &t.OccurredAt, &t.RecordedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (r *MasteryRepo) queryBandSettings(ctx context.Context, sql string, args ...interface{}) ([]domain.MasteryBandSetting, error) {
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.MasteryBandSetting
+	for rows.Next() {
+		var s domain.MasteryBandSetting
+		if err := rows.Scan(&s.Scope, &s.ScopeID, &s.Developing, &s.Proficient, &s.Advanced, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
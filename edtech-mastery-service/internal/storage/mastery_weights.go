@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+// AssignmentWeights returns the assignment's standard weights, or nil if none are configured.
+func (r *MasteryRepo) AssignmentWeights(ctx context.Context, assignmentID string) (*domain.AssignmentWeights, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT standard_id, weight, updated_at FROM assignment_standard_weights WHERE assignment_id = $1`,
+		assignmentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out *domain.AssignmentWeights
+	for rows.Next() {
+		if out == nil {
+			out = &domain.AssignmentWeights{AssignmentID: assignmentID, Weights: map[string]float64{}}
+		}
+		var (
+			std       string
+			weight    float64
+			updatedAt time.Time
+		)
+		if err := rows.Scan(&std, &weight, &updatedAt); err != nil {
+			return nil, err
+		}
+		out.Weights[std] = weight
+		if updatedAt.After(out.UpdatedAt) {
+			out.UpdatedAt = updatedAt
+		}
+	}
+	return out, rows.Err()
+}
+
+// ReplaceAssignmentWeights makes w.Weights the assignment's complete set of standard weights.
+func (r *MasteryRepo) ReplaceAssignmentWeights(ctx context.Context, w domain.AssignmentWeights) error {
+	stds := make([]string, 0, len(w.Weights))
+	weights := make([]float64, 0, len(w.Weights))
+	for std, weight := range w.Weights {
+		stds = append(stds, std)
+		weights = append(weights, weight)
+	}
+	_, err := r.db.Exec(ctx,
+		`WITH removed AS (
+		     DELETE FROM assignment_standard_weights WHERE assignment_id = $1 AND NOT (standard_id = ANY($2))
+		 )
+		 INSERT INTO assignment_standard_weights (assignment_id, standard_id, weight, updated_at)
+		 SELECT $1, s.standard_id, s.weight, NOW() FROM unnest($2::text[], $3::float8[]) AS s(standard_id, weight)
+		 ON CONFLICT (assignment_id, standard_id) DO UPDATE SET weight = EXCLUDED.weight, updated_at = NOW()`,
+		w.AssignmentID, stds, weights,
+	)
+	return err
+}
+
+// DeleteAssignmentWeights reports false if the assignment had no weights.
+func (r *MasteryRepo) DeleteAssignmentWeights(ctx context.Context, assignmentID string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM assignment_standard_weights WHERE assignment_id = $1`, assignmentID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -2,28 +2,45 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/edtech-mastery/student-progress-service/internal/domain"
 )
 
+// ErrLeaseLost means the worker no longer holds the claim on an outbox row.
+var ErrLeaseLost = errors.New("outbox lease lost")
+
 type OutboxRepo struct {
-	pool *pgxpool.Pool
+	db DBTX
 }
 
 func NewOutboxRepo(pool *pgxpool.Pool) *OutboxRepo {
-	return &OutboxRepo{pool: pool}
+	return &OutboxRepo{db: pool}
+}
+
+// WithTx returns a copy of the repo that runs its statements inside tx.
+func (r *OutboxRepo) WithTx(tx pgx.Tx) *OutboxRepo {
+	return &OutboxRepo{db: tx}
 }
 
-func (r *OutboxRepo) ClaimNext(ctx context.Context, limit int) ([]domain.OutboxItem, error) {
-	rows, err := r.pool.Query(ctx,
-		`UPDATE event_outbox SET status = 'processing', attempts = attempts + 1
+// ClaimNext moves up to limit due pending rows to processing under a lease held by workerID.
+// If the lease expires before the row is marked, ReleaseExpiredLeases hands it to another worker.
+func (r *OutboxRepo) ClaimNext(ctx context.Context, limit int, workerID string, lease time.Duration) ([]domain.OutboxItem, error) {
+	rows, err := r.db.Query(ctx,
+		`UPDATE event_outbox
+		 SET status = 'processing', attempts = attempts + 1,
+		     locked_by = $2, locked_until = NOW() + make_interval(secs => $3)
 		 WHERE id IN (
-		   SELECT id FROM event_outbox WHERE status = 'pending' ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED
+		   SELECT id FROM event_outbox
+		   WHERE status = 'pending' AND next_attempt_at <= NOW()
+		   ORDER BY next_attempt_at, id LIMIT $1 FOR UPDATE SKIP LOCKED
 		 )
-		 RETURNING id, event_db_id, status, attempts, last_error, created_at, processed_at`,
-		limit,
+		 RETURNING id, event_db_id, status, attempts, last_error, created_at, processed_at, dead_at, next_attempt_at, locked_by, locked_until`,
+		limit, workerID, lease.Seconds(),
 	)
 	if err != nil {
 		return nil, err
@@ -33,7 +50,7 @@ func (r *OutboxRepo) ClaimNext(ctx context.Context, limit int) ([]domain.OutboxI
 	var items []domain.OutboxItem
 	for rows.Next() {
 		var o domain.OutboxItem
-		err := rows.Scan(&o.ID, &o.EventDBID, &o.Status, &o.Attempts, &o.LastError, &o.CreatedAt, &o.ProcessedAt)
+		err := rows.Scan(&o.ID, &o.EventDBID, &o.Status, &o.Attempts, &o.LastError, &o.CreatedAt, &o.ProcessedAt, &o.DeadAt, &o.NextAttemptAt, &o.LockedBy, &o.LockedUntil)
 		if err != nil {
 			return nil, err
 		}
@@ -42,18 +59,156 @@ func (r *OutboxRepo) ClaimNext(ctx context.Context, limit int) ([]domain.OutboxI
 	return items, rows.Err()
 }
 
-func (r *OutboxRepo) MarkProcessed(ctx context.Context, outboxID int64) error {
-	_, err := r.pool.Exec(ctx,
-		`UPDATE event_outbox SET status = 'processed', processed_at = NOW() WHERE id = $1`,
+// MarkProcessed completes a row claimed by workerID. It returns ErrLeaseLost if the lease
+// was reaped in the meantime, since another worker may now own the row.
+func (r *OutboxRepo) MarkProcessed(ctx context.Context, outboxID int64, workerID string) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE event_outbox SET status = 'processed', processed_at = NOW(), locked_by = NULL, locked_until = NULL
+		 WHERE id = $1 AND status = 'processing' AND locked_by = $2`,
+		outboxID, workerID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// MarkFailed records errMsg and schedules the row for another attempt after retryIn, or moves it
+// to dead once maxAttempts claims have been used up. dead reports which of the two happened.
+// Like MarkProcessed it only applies while workerID still holds the lease.
+func (r *OutboxRepo) MarkFailed(ctx context.Context, outboxID int64, workerID, errMsg string, maxAttempts int, retryIn time.Duration) (dead bool, err error) {
+	var status string
+	err = r.db.QueryRow(ctx,
+		`UPDATE event_outbox
+		 SET status = CASE WHEN attempts >= $4 THEN 'dead' ELSE 'pending' END,
+		     dead_at = CASE WHEN attempts >= $4 THEN NOW() END,
+		     next_attempt_at = NOW() + make_interval(secs => $5),
+		     last_error = $3,
+		     locked_by = NULL, locked_until = NULL
+		 WHERE id = $1 AND status = 'processing' AND locked_by = $2
+		 RETURNING status`,
+		outboxID, workerID, errMsg, maxAttempts, retryIn.Seconds(),
+	).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrLeaseLost
+	}
+	if err != nil {
+		return false, err
+	}
+	return status == domain.OutboxStatusDead, nil
+}
+
+// ReleaseExpiredLeases returns processing rows whose lease has expired (their worker died or
+// stalled) to pending, or dead-letters them if they have no attempts left.
+func (r *OutboxRepo) ReleaseExpiredLeases(ctx context.Context, maxAttempts int) (released, dead int, err error) {
+	rows, err := r.db.Query(ctx,
+		`UPDATE event_outbox
+		 SET status = CASE WHEN attempts >= $1 THEN 'dead' ELSE 'pending' END,
+		     dead_at = CASE WHEN attempts >= $1 THEN NOW() END,
+		     next_attempt_at = NOW(),
+		     last_error = 'lease expired (held by ' || COALESCE(locked_by, 'unknown') || ')',
+		     locked_by = NULL, locked_until = NULL
+		 WHERE status = 'processing' AND (locked_until IS NULL OR locked_until < NOW())
+		 RETURNING status`,
+		maxAttempts,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		if err := rows.Scan(&status); err != nil {
+			return 0, 0, err
+		}
+		if status == domain.OutboxStatusDead {
+			dead++
+		} else {
+			released++
+		}
+	}
+	return released, dead, rows.Err()
+}
+
+// ListDead returns dead-lettered rows with id > afterID, oldest first.
+func (r *OutboxRepo) ListDead(ctx context.Context, afterID int64, limit int) ([]domain.DeadLetter, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := r.db.Query(ctx,
+		`SELECT o.id, o.event_db_id, e.event_id, e.source, e.type, o.attempts, o.last_error, o.created_at, o.dead_at
+		 FROM event_outbox o
+		 JOIN events e ON e.id = o.event_db_id
+		 WHERE o.status = 'dead' AND o.id > $1
+		 ORDER BY o.id LIMIT $2`,
+		afterID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.DeadLetter
+	for rows.Next() {
+		var d domain.DeadLetter
+		if err := rows.Scan(&d.OutboxID, &d.EventDBID, &d.EventID, &d.Source, &d.Type, &d.Attempts, &d.LastError, &d.CreatedAt, &d.DeadAt); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// GetDead returns one dead-lettered row with its event payload, or nil if the row is not dead.
+func (r *OutboxRepo) GetDead(ctx context.Context, outboxID int64) (*domain.DeadLetter, error) {
+	var d domain.DeadLetter
+	var payload []byte
+	err := r.db.QueryRow(ctx,
+		`SELECT o.id, o.event_db_id, e.event_id, e.source, e.type, o.attempts, o.last_error, o.created_at, o.dead_at, e.payload
+		 FROM event_outbox o
+		 JOIN events e ON e.id = o.event_db_id
+		 WHERE o.id = $1 AND o.status = 'dead'`,
+		outboxID,
+	).Scan(&d.OutboxID, &d.EventDBID, &d.EventID, &d.Source, &d.Type, &d.Attempts, &d.LastError, &d.CreatedAt, &d.DeadAt, &payload)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	return &d, nil
+}
+
+// RequeueDead returns a dead row to pending with a fresh attempt budget. The last error is kept for reference.
+func (r *OutboxRepo) RequeueDead(ctx context.Context, outboxID int64) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE event_outbox SET status = 'pending', attempts = 0, dead_at = NULL, next_attempt_at = NOW()
+		 WHERE id = $1 AND status = 'dead'`,
 		outboxID,
 	)
-	return err
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	_, err = r.db.Exec(ctx, `SELECT pg_notify($1, '')`, OutboxNotifyChannel)
+	return true, err
 }
 
-func (r *OutboxRepo) MarkFailed(ctx context.Context, outboxID int64, errMsg string) error {
-	_, err := r.pool.Exec(ctx,
-		`UPDATE event_outbox SET status = 'pending', last_error = $2 WHERE id = $1`,
-		outboxID, errMsg,
+// DiscardDead marks a dead row as discarded so it is never processed nor listed again.
+func (r *OutboxRepo) DiscardDead(ctx context.Context, outboxID int64) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE event_outbox SET status = 'discarded', processed_at = NOW()
+		 WHERE id = $1 AND status = 'dead'`,
+		outboxID,
 	)
-	return err
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
 }
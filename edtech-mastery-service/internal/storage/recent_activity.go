@@ -21,10 +21,10 @@ func (r *RecentActivityRepo) GetRecentByClass(ctx context.Context, classID strin
 		limit = 20
 	}
 	rows, err := r.pool.Query(ctx,
-		`SELECT e.type, e.payload->>'student_id', e.payload->>'assignment_id', e.created_at
+		`SELECT e.type, e.student_id, e.assignment_id, e.occurred_at, e.created_at
 		 FROM events e
-		 WHERE e.payload->>'class_id' = $1
-		 ORDER BY e.created_at DESC LIMIT $2`,
+		 WHERE e.class_id = $1 AND e.type <> 'ITEM_RESPONDED' -- one per quiz question; too fine-grained for the feed
+		 ORDER BY e.occurred_at DESC, e.id DESC LIMIT $2`,
 		classID, limit,
 	)
 	if err != nil {
@@ -36,7 +36,7 @@ func (r *RecentActivityRepo) GetRecentByClass(ctx context.Context, classID strin
 	for rows.Next() {
 		var a domain.RecentActivity
 		var studentID, assignmentID *string
-		if err := rows.Scan(&a.EventType, &studentID, &assignmentID, &a.CreatedAt); err != nil {
+		if err := rows.Scan(&a.EventType, &studentID, &assignmentID, &a.OccurredAt, &a.CreatedAt); err != nil {
 			return nil, err
 		}
 		if studentID != nil {
@@ -2,38 +2,163 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/edtech-mastery/student-progress-service/internal/domain"
 )
 
 type RiskRepo struct {
-	pool *pgxpool.Pool
+	db DBTX
 }
 
 func NewRiskRepo(pool *pgxpool.Pool) *RiskRepo {
-	return &RiskRepo{pool: pool}
+	return &RiskRepo{db: pool}
 }
 
-func (r *RiskRepo) UpsertRiskFlag(ctx context.Context, studentID, classID, reason string) error {
-	_, err := r.pool.Exec(ctx,
-		`INSERT INTO risk_flags (student_id, class_id, reason, computed_at)
-		 VALUES ($1, $2, $3, NOW())
-		 ON CONFLICT (student_id, class_id, reason) DO UPDATE SET computed_at = NOW()`,
-		studentID, classID, reason,
+// WithTx returns a copy of the repo that runs its statements inside tx.
+func (r *RiskRepo) WithTx(tx pgx.Tx) *RiskRepo {
+	return &RiskRepo{db: tx}
+}
+
+// riskFlagColumns are the risk_flags columns queryRiskFlags scans, in order.
+const riskFlagColumns = `student_id, class_id, reason, evidence, status, active, first_flagged_at,
+		acknowledged_at, COALESCE(acknowledged_by, ''), COALESCE(note, ''), resolved_at, computed_at`
+
+// RiskFlagsForClass returns the class's stored flags in every status, locked for the rest of the transaction
+// so a teacher's acknowledgement cannot interleave with a recompute.
+func (r *RiskRepo) RiskFlagsForClass(ctx context.Context, classID string) ([]domain.RiskFlag, error) {
+	return r.queryRiskFlags(ctx,
+		`SELECT `+riskFlagColumns+` FROM risk_flags WHERE class_id = $1 ORDER BY student_id, reason FOR UPDATE`,
+		classID,
 	)
-	return err
 }
 
-func (r *RiskRepo) DeleteRiskFlagsForClass(ctx context.Context, classID string) error {
-	_, err := r.pool.Exec(ctx, `DELETE FROM risk_flags WHERE class_id = $1`, classID)
+// RiskFlagsForStudent returns the student's flags in the class in every status.
+func (r *RiskRepo) RiskFlagsForStudent(ctx context.Context, classID, studentID string) ([]domain.RiskFlag, error) {
+	return r.queryRiskFlags(ctx,
+		`SELECT `+riskFlagColumns+` FROM risk_flags WHERE class_id = $1 AND student_id = $2 ORDER BY reason`,
+		classID, studentID,
+	)
+}
+
+// GetRiskFlag returns nil if the student has never been flagged for reason in the class.
+func (r *RiskRepo) GetRiskFlag(ctx context.Context, classID, studentID, reason string) (*domain.RiskFlag, error) {
+	flags, err := r.queryRiskFlags(ctx,
+		`SELECT `+riskFlagColumns+` FROM risk_flags WHERE class_id = $1 AND student_id = $2 AND reason = $3`,
+		classID, studentID, reason,
+	)
+	if err != nil || len(flags) == 0 {
+		return nil, err
+	}
+	return &flags[0], nil
+}
+
+func (r *RiskRepo) queryRiskFlags(ctx context.Context, sql string, args ...interface{}) ([]domain.RiskFlag, error) {
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.RiskFlag
+	for rows.Next() {
+		var f domain.RiskFlag
+		if err := rows.Scan(&f.StudentID, &f.ClassID, &f.Reason, &f.Evidence, &f.Status, &f.Active, &f.FirstFlaggedAt,
+			&f.AcknowledgedAt, &f.AcknowledgedBy, &f.Note, &f.ResolvedAt, &f.ComputedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// SaveRiskFlag writes the flag as computed by the worker. With changed set, its new status is also recorded in
+// the flag's history.
+func (r *RiskRepo) SaveRiskFlag(ctx context.Context, f domain.RiskFlag, changed bool) error {
+	var id int64
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO risk_flags (student_id, class_id, reason, evidence, status, active, first_flagged_at,
+		                         acknowledged_at, acknowledged_by, note, resolved_at, computed_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, NOW())
+		 ON CONFLICT (student_id, class_id, reason) DO UPDATE SET
+		   evidence = $4, status = $5, active = $6, first_flagged_at = $7, acknowledged_at = $8,
+		   acknowledged_by = NULLIF($9, ''), note = NULLIF($10, ''), resolved_at = $11, computed_at = NOW()
+		 RETURNING id`,
+		f.StudentID, f.ClassID, f.Reason, f.Evidence, f.Status, f.Active, f.FirstFlaggedAt,
+		f.AcknowledgedAt, f.AcknowledgedBy, f.Note, f.ResolvedAt,
+	).Scan(&id)
+	if err != nil || !changed {
+		return err
+	}
+	_, err = r.db.Exec(ctx,
+		`INSERT INTO risk_flag_history (flag_id, status, evidence) VALUES ($1, $2, $3)`,
+		id, f.Status, f.Evidence,
+	)
 	return err
 }
 
+// SetRiskFlagStatus moves an open or acknowledged flag to status on behalf of actor, recording it in the flag's
+// history. An empty note keeps the flag's current note. It reports false if there is no such open or acknowledged flag.
+func (r *RiskRepo) SetRiskFlagStatus(ctx context.Context, classID, studentID, reason, status, actor, note string) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`WITH updated AS (
+			UPDATE risk_flags SET
+			  status = $4,
+			  acknowledged_at = CASE WHEN $4 = 'acknowledged' THEN NOW() ELSE acknowledged_at END,
+			  acknowledged_by = CASE WHEN $4 = 'acknowledged' THEN NULLIF($5, '') ELSE acknowledged_by END,
+			  resolved_at = CASE WHEN $4 = 'resolved' THEN NOW() ELSE resolved_at END,
+			  note = COALESCE(NULLIF($6, ''), note)
+			WHERE class_id = $1 AND student_id = $2 AND reason = $3 AND status IN ('open', 'acknowledged')
+			RETURNING id, evidence
+		 )
+		 INSERT INTO risk_flag_history (flag_id, status, actor, note, evidence)
+		 SELECT id, $4, NULLIF($5, ''), NULLIF($6, ''), evidence FROM updated`,
+		classID, studentID, reason, status, actor, note,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RiskFlagHistory returns the status changes of the student's flags in the class, oldest first.
+func (r *RiskRepo) RiskFlagHistory(ctx context.Context, classID, studentID string) ([]domain.RiskFlagChange, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT f.reason, h.status, COALESCE(h.actor, ''), COALESCE(h.note, ''), h.evidence, h.created_at
+		 FROM risk_flag_history h
+		 JOIN risk_flags f ON f.id = h.flag_id
+		 WHERE f.class_id = $1 AND f.student_id = $2
+		 ORDER BY h.created_at, h.id`,
+		classID, studentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.RiskFlagChange
+	for rows.Next() {
+		var c domain.RiskFlagChange
+		if err := rows.Scan(&c.Reason, &c.Status, &c.Actor, &c.Note, &c.Evidence, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// GetAtRiskByClass returns the students with open or acknowledged flags in the class.
 func (r *RiskRepo) GetAtRiskByClass(ctx context.Context, classID string) ([]domain.AtRiskStudent, error) {
-	rows, err := r.pool.Query(ctx,
-		`SELECT student_id, reason FROM risk_flags WHERE class_id = $1 ORDER BY student_id`,
+	rows, err := r.db.Query(ctx,
+		`SELECT student_id, reason, evidence, status, first_flagged_at
+		 FROM risk_flags
+		 WHERE class_id = $1 AND status IN ('open', 'acknowledged')
+		 ORDER BY student_id, reason`,
 		classID,
 	)
 	if err != nil {
@@ -41,20 +166,185 @@ func (r *RiskRepo) GetAtRiskByClass(ctx context.Context, classID string) ([]doma
 	}
 	defer rows.Close()
 
-	byStudent := make(map[string][]string)
+	var out []domain.AtRiskStudent
 	for rows.Next() {
-		var studentID, reason string
-		if err := rows.Scan(&studentID, &reason); err != nil {
+		var studentID, reason, status string
+		var evidence []byte
+		var firstFlaggedAt time.Time
+		if err := rows.Scan(&studentID, &reason, &evidence, &status, &firstFlaggedAt); err != nil {
 			return nil, err
 		}
-		byStudent[studentID] = append(byStudent[studentID], reason)
+		if len(out) == 0 || out[len(out)-1].StudentID != studentID {
+			out = append(out, domain.AtRiskStudent{
+				StudentID: studentID, Status: make(map[string]string), FirstFlaggedAt: firstFlaggedAt,
+			})
+		}
+		s := &out[len(out)-1]
+		s.Reasons = append(s.Reasons, reason)
+		s.Status[reason] = status
+		if firstFlaggedAt.Before(s.FirstFlaggedAt) {
+			s.FirstFlaggedAt = firstFlaggedAt
+		}
+		if evidence != nil {
+			if s.Evidence == nil {
+				s.Evidence = make(map[string]json.RawMessage)
+			}
+			s.Evidence[reason] = evidence
+		}
 	}
-	if err := rows.Err(); err != nil {
+	return out, rows.Err()
+}
+
+// notExcused filters effective_events e down to events whose (student, assignment) pair is not excused.
+const notExcused = `NOT EXISTS (
+			SELECT 1 FROM excused_pairs x
+			WHERE x.class_id = e.class_id AND x.student_id = e.student_id AND x.assignment_id = e.assignment_id
+		)`
+
+// MissingCounts returns, per student with any, how many assignments are past their due date without a submission,
+// as maintained on class_rollup_pairs by the worker and its overdue sweep. Excused assignments do not count.
+func (r *RiskRepo) MissingCounts(ctx context.Context, classID string) (map[string]int, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT student_id, COUNT(*)
+		 FROM class_rollup_pairs
+		 WHERE class_id = $1 AND assigned AND NOT excused AND status = 'missing'
+		 GROUP BY student_id`,
+		classID,
+	)
+	if err != nil {
 		return nil, err
 	}
-	var out []domain.AtRiskStudent
-	for id, reasons := range byStudent {
-		out = append(out, domain.AtRiskStudent{StudentID: id, Reasons: reasons})
+	defer rows.Close()
+
+	out := make(map[string]int)
+	for rows.Next() {
+		var studentID string
+		var n int
+		if err := rows.Scan(&studentID, &n); err != nil {
+			return nil, err
+		}
+		out[studentID] = n
+	}
+	return out, rows.Err()
+}
+
+// CompletionRates returns each student's completion rate in the class: distinct graded over distinct assigned
+// assignments. Excused assignments are left out of both sides; students with nothing assigned are left out.
+func (r *RiskRepo) CompletionRates(ctx context.Context, classID string) (map[string]float64, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT student_id,
+		        COUNT(DISTINCT CASE WHEN type = 'SUBMISSION_GRADED' THEN assignment_id END)::float /
+		        COUNT(DISTINCT CASE WHEN type = 'ASSIGNMENT_ASSIGNED' THEN assignment_id END)
+		 FROM effective_events e
+		 WHERE class_id = $1 AND `+notExcused+`
+		 GROUP BY student_id
+		 HAVING COUNT(DISTINCT CASE WHEN type = 'ASSIGNMENT_ASSIGNED' THEN assignment_id END) > 0`,
+		classID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]float64)
+	for rows.Next() {
+		var studentID string
+		var rate float64
+		if err := rows.Scan(&studentID, &rate); err != nil {
+			return nil, err
+		}
+		out[studentID] = rate
+	}
+	return out, rows.Err()
+}
+
+// RecentScores returns each student's latest n normalized graded scores in the class, newest first (by event
+// timestamp). It reads effective_events, so regrades replace the original score and retracted events are ignored.
+func (r *RiskRepo) RecentScores(ctx context.Context, classID string, n int) (map[string][]float64, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT student_id, score FROM (
+			SELECT student_id, normalized_score AS score,
+			       row_number() OVER (PARTITION BY student_id ORDER BY occurred_at DESC, id DESC) AS rn
+			FROM effective_events
+			WHERE type = 'SUBMISSION_GRADED' AND class_id = $1 AND normalized_score IS NOT NULL
+		 ) g
+		 WHERE rn <= $2
+		 ORDER BY student_id, rn`,
+		classID, n,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string][]float64)
+	for rows.Next() {
+		var studentID string
+		var score float64
+		if err := rows.Scan(&studentID, &score); err != nil {
+			return nil, err
+		}
+		out[studentID] = append(out[studentID], score)
+	}
+	return out, rows.Err()
+}
+
+// RiskSettingsFor returns the class's district (empty if unassigned) and the settings for the class and that district.
+func (r *RiskRepo) RiskSettingsFor(ctx context.Context, classID string) (string, []domain.RiskSetting, error) {
+	var districtID string
+	err := r.db.QueryRow(ctx, `SELECT district_id FROM class_districts WHERE class_id = $1`, classID).Scan(&districtID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, err
+	}
+	settings, err := r.queryRiskSettings(ctx,
+		`SELECT scope, scope_id, missing_count, trend_window, median_margin, enabled_rules, updated_at FROM risk_settings
+		 WHERE (scope = 'class' AND scope_id = $1) OR (scope = 'district' AND scope_id = $2)`,
+		classID, districtID,
+	)
+	return districtID, settings, err
+}
+
+func (r *RiskRepo) ListRiskSettings(ctx context.Context) ([]domain.RiskSetting, error) {
+	return r.queryRiskSettings(ctx,
+		`SELECT scope, scope_id, missing_count, trend_window, median_margin, enabled_rules, updated_at
+		 FROM risk_settings ORDER BY scope, scope_id`,
+	)
+}
+
+func (r *RiskRepo) UpsertRiskSetting(ctx context.Context, s domain.RiskSetting) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO risk_settings (scope, scope_id, missing_count, trend_window, median_margin, enabled_rules, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		 ON CONFLICT (scope, scope_id) DO UPDATE SET
+		   missing_count = $3, trend_window = $4, median_margin = $5, enabled_rules = $6, updated_at = NOW()`,
+		s.Scope, s.ScopeID, s.MissingCount, s.TrendWindow, s.MedianMargin, s.EnabledRules,
+	)
+	return err
+}
+
+// DeleteRiskSetting reports false if there was no setting for the scope.
+func (r *RiskRepo) DeleteRiskSetting(ctx context.Context, scope, scopeID string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM risk_settings WHERE scope = $1 AND scope_id = $2`, scope, scopeID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *RiskRepo) queryRiskSettings(ctx context.Context, sql string, args ...interface{}) ([]domain.RiskSetting, error) {
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.RiskSetting
+	for rows.Next() {
+		var s domain.RiskSetting
+		if err := rows.Scan(&s.Scope, &s.ScopeID, &s.MissingCount, &s.TrendWindow, &s.MedianMargin, &s.EnabledRules, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
 	}
-	return out, nil
+	return out, rows.Err()
 }
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -11,15 +12,20 @@ import (
 )
 
 type RollupsRepo struct {
-	pool *pgxpool.Pool
+	db DBTX
 }
 
 func NewRollupsRepo(pool *pgxpool.Pool) *RollupsRepo {
-	return &RollupsRepo{pool: pool}
+	return &RollupsRepo{db: pool}
+}
+
+// WithTx returns a copy of the repo that runs its statements inside tx.
+func (r *RollupsRepo) WithTx(tx pgx.Tx) *RollupsRepo {
+	return &RollupsRepo{db: tx}
 }
 
 func (r *RollupsRepo) UpsertClassRollup(ctx context.Context, classID string, completionRate float64, avgScore *float64) error {
-	_, err := r.pool.Exec(ctx,
+	_, err := r.db.Exec(ctx,
 		`INSERT INTO class_rollups (class_id, completion_rate, avg_score, updated_at)
 		 VALUES ($1, $2, $3, NOW())
 		 ON CONFLICT (class_id) DO UPDATE SET completion_rate = $2, avg_score = $3, updated_at = NOW()`,
@@ -30,7 +36,7 @@ func (r *RollupsRepo) UpsertClassRollup(ctx context.Context, classID string, com
 
 func (r *RollupsRepo) GetClassRollup(ctx context.Context, classID string) (*domain.ClassRollup, error) {
 	var c domain.ClassRollup
-	err := r.pool.QueryRow(ctx,
+	err := r.db.QueryRow(ctx,
 		`SELECT class_id, completion_rate, avg_score, updated_at FROM class_rollups WHERE class_id = $1`,
 		classID,
 	).Scan(&c.ClassID, &c.CompletionRate, &c.AvgScore, &c.UpdatedAt)
@@ -42,3 +48,266 @@ func (r *RollupsRepo) GetClassRollup(ctx context.Context, classID string) (*doma
 	}
 	return &c, nil
 }
+
+// InsertContribution records that an event is being folded into its class counters.
+// It returns false if the event was already applied, in which case the caller must not apply it again.
+func (r *RollupsRepo) InsertContribution(ctx context.Context, c domain.RollupContribution) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`INSERT INTO class_rollup_contributions (event_db_id, class_id, student_id, assignment_id, event_type, score)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (event_db_id) DO NOTHING`,
+		c.EventDBID, c.ClassID, c.StudentID, c.AssignmentID, c.EventType, c.Score,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// DeleteContribution removes an event's contribution and returns it, or nil if the event never contributed.
+func (r *RollupsRepo) DeleteContribution(ctx context.Context, eventDBID int64) (*domain.RollupContribution, error) {
+	c := domain.RollupContribution{EventDBID: eventDBID}
+	err := r.db.QueryRow(ctx,
+		`DELETE FROM class_rollup_contributions WHERE event_db_id = $1
+		 RETURNING class_id, student_id, assignment_id, event_type, score`,
+		eventDBID,
+	).Scan(&c.ClassID, &c.StudentID, &c.AssignmentID, &c.EventType, &c.Score)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SetContributionScore replaces the score an event contributed and returns the contribution as it was,
+// or nil if the event never contributed.
+func (r *RollupsRepo) SetContributionScore(ctx context.Context, eventDBID int64, score float64) (*domain.RollupContribution, error) {
+	c := domain.RollupContribution{EventDBID: eventDBID}
+	err := r.db.QueryRow(ctx,
+		`UPDATE class_rollup_contributions c SET score = $2
+		 FROM (SELECT event_db_id, score FROM class_rollup_contributions WHERE event_db_id = $1 FOR UPDATE) old
+		 WHERE c.event_db_id = old.event_db_id
+		 RETURNING c.class_id, c.student_id, c.assignment_id, c.event_type, old.score`,
+		eventDBID, score,
+	).Scan(&c.ClassID, &c.StudentID, &c.AssignmentID, &c.EventType, &c.Score)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// PairFromContributions derives a pair's state from its remaining contributions, as RebuildCounters does.
+// The pair is excused if its latest excuse or un-excuse event (by timestamp) is an excuse. Status is left to the caller.
+func (r *RollupsRepo) PairFromContributions(ctx context.Context, classID, studentID, assignmentID string) (domain.RollupPair, error) {
+	p := domain.RollupPair{ClassID: classID, StudentID: studentID, AssignmentID: assignmentID}
+	err := r.db.QueryRow(ctx,
+		`SELECT `+pairAggregates+`
+		 FROM class_rollup_contributions c
+		 JOIN events e ON e.id = c.event_db_id
+		 WHERE c.class_id = $1 AND c.student_id = $2 AND c.assignment_id = $3`,
+		classID, studentID, assignmentID,
+	).Scan(&p.Assigned, &p.GradedCount, &p.Excused, &p.AssignedAt, &p.DueAt, &p.SubmittedAt)
+	return p, err
+}
+
+// pairAggregates derive a pair's assigned, graded_count, excused, assigned_at, due_at and submitted_at from its
+// contributions c joined to their events e.
+const pairAggregates = `COALESCE(BOOL_OR(c.event_type = 'ASSIGNMENT_ASSIGNED'), false),
+		        COUNT(*) FILTER (WHERE c.event_type = 'SUBMISSION_GRADED'),
+		        COALESCE((ARRAY_AGG(c.event_type = 'ASSIGNMENT_EXCUSED' ORDER BY e.occurred_at DESC, e.id DESC)
+		          FILTER (WHERE c.event_type IN ('ASSIGNMENT_EXCUSED', 'ASSIGNMENT_UNEXCUSED')))[1], false),
+		        MAX(e.occurred_at) FILTER (WHERE c.event_type = 'ASSIGNMENT_ASSIGNED'),
+		        (ARRAY_AGG((e.payload->>'due_at')::timestamptz ORDER BY e.occurred_at DESC, e.id DESC)
+		          FILTER (WHERE c.event_type = 'ASSIGNMENT_ASSIGNED'))[1],
+		        MIN(e.occurred_at) FILTER (WHERE c.event_type IN ('SUBMISSION_CREATED', 'SUBMISSION_GRADED'))`
+
+// pairStatusSQL is rollups' pairStatus for a class_rollup_pairs row, as of NOW().
+const pairStatusSQL = `CASE
+		    WHEN submitted_at IS NOT NULL THEN CASE WHEN submitted_at > due_at THEN 'late' ELSE 'on_time' END
+		    WHEN due_at <= NOW() THEN 'missing'
+		    ELSE 'not_yet_due'
+		  END`
+
+// LockPair returns the pair state, locked until the end of the transaction. A pair not seen yet is returned zero-valued.
+func (r *RollupsRepo) LockPair(ctx context.Context, classID, studentID, assignmentID string) (domain.RollupPair, error) {
+	p := domain.RollupPair{ClassID: classID, StudentID: studentID, AssignmentID: assignmentID}
+	err := r.db.QueryRow(ctx,
+		`SELECT assigned, graded_count, excused, assigned_at, due_at, submitted_at, status FROM class_rollup_pairs
+		 WHERE class_id = $1 AND student_id = $2 AND assignment_id = $3
+		 FOR UPDATE`,
+		classID, studentID, assignmentID,
+	).Scan(&p.Assigned, &p.GradedCount, &p.Excused, &p.AssignedAt, &p.DueAt, &p.SubmittedAt, &p.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, nil
+	}
+	return p, err
+}
+
+func (r *RollupsRepo) SavePair(ctx context.Context, p domain.RollupPair) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO class_rollup_pairs (class_id, student_id, assignment_id, assigned, graded_count, excused,
+		                                 assigned_at, due_at, submitted_at, status)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 ON CONFLICT (class_id, student_id, assignment_id) DO UPDATE SET
+		   assigned = $4, graded_count = $5, excused = $6, assigned_at = $7, due_at = $8, submitted_at = $9, status = $10`,
+		p.ClassID, p.StudentID, p.AssignmentID, p.Assigned, p.GradedCount, p.Excused,
+		p.AssignedAt, p.DueAt, p.SubmittedAt, p.Status,
+	)
+	return err
+}
+
+// AddCounters adds delta to the class counters, creating them on first use.
+func (r *RollupsRepo) AddCounters(ctx context.Context, delta domain.RollupCounters) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO class_rollup_counters (class_id, assigned_pairs, graded_pairs, score_sum, score_count, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, NOW())
+		 ON CONFLICT (class_id) DO UPDATE SET
+		   assigned_pairs = class_rollup_counters.assigned_pairs + $2,
+		   graded_pairs = class_rollup_counters.graded_pairs + $3,
+		   score_sum = class_rollup_counters.score_sum + $4,
+		   score_count = class_rollup_counters.score_count + $5,
+		   updated_at = NOW()`,
+		delta.ClassID, delta.AssignedPairs, delta.GradedPairs, delta.ScoreSum, delta.ScoreCount,
+	)
+	return err
+}
+
+func (r *RollupsRepo) GetCounters(ctx context.Context, classID string) (domain.RollupCounters, error) {
+	c := domain.RollupCounters{ClassID: classID}
+	err := r.db.QueryRow(ctx,
+		`SELECT assigned_pairs, graded_pairs, score_sum, score_count FROM class_rollup_counters WHERE class_id = $1`,
+		classID,
+	).Scan(&c.AssignedPairs, &c.GradedPairs, &c.ScoreSum, &c.ScoreCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c, nil
+	}
+	return c, err
+}
+
+// MarkMissing moves the class's pairs that are past due without a submission from not_yet_due to missing.
+// It returns how many pairs changed.
+func (r *RollupsRepo) MarkMissing(ctx context.Context, classID string, now time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE class_rollup_pairs SET status = 'missing'
+		 WHERE class_id = $1 AND status = 'not_yet_due' AND due_at <= $2`,
+		classID, now,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ClassesWithOverdue returns the classes with pairs MarkMissing would change.
+func (r *RollupsRepo) ClassesWithOverdue(ctx context.Context, now time.Time) ([]string, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT DISTINCT class_id FROM class_rollup_pairs WHERE status = 'not_yet_due' AND due_at <= $1`,
+		now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var classID string
+		if err := rows.Scan(&classID); err != nil {
+			return nil, err
+		}
+		out = append(out, classID)
+	}
+	return out, rows.Err()
+}
+
+// StatusCounts returns how many of the class's assigned, not excused pairs are late and missing.
+func (r *RollupsRepo) StatusCounts(ctx context.Context, classID string) (late, missing int64, err error) {
+	err = r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FILTER (WHERE status = 'late'), COUNT(*) FILTER (WHERE status = 'missing')
+		 FROM class_rollup_pairs
+		 WHERE class_id = $1 AND assigned AND NOT excused`,
+		classID,
+	).Scan(&late, &missing)
+	return late, missing, err
+}
+
+// AssignmentStatusQuery filters ListAssignmentStatuses. Empty fields match everything.
+type AssignmentStatusQuery struct {
+	ClassID   string
+	StudentID string
+	Status    string
+	Limit     int
+}
+
+// ListAssignmentStatuses returns the class's assigned pairs with their status, soonest due first.
+func (r *RollupsRepo) ListAssignmentStatuses(ctx context.Context, q AssignmentStatusQuery) ([]domain.AssignmentStatus, error) {
+	if q.Limit <= 0 || q.Limit > 500 {
+		q.Limit = 100
+	}
+	rows, err := r.db.Query(ctx,
+		`SELECT student_id, assignment_id, status, due_at, submitted_at, excused
+		 FROM class_rollup_pairs
+		 WHERE class_id = $1 AND assigned
+		   AND ($2 = '' OR student_id = $2) AND ($3 = '' OR status = $3)
+		 ORDER BY due_at NULLS LAST, student_id, assignment_id
+		 LIMIT $4`,
+		q.ClassID, q.StudentID, q.Status, q.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.AssignmentStatus
+	for rows.Next() {
+		var a domain.AssignmentStatus
+		if err := rows.Scan(&a.StudentID, &a.AssignmentID, &a.Status, &a.DueAt, &a.SubmittedAt, &a.Excused); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// RebuildCounters discards the class's contributions, pairs and counters and rebuilds them from the events table,
+// as corrected (effective_events).
+// Run it inside a transaction so readers never see the class half rebuilt.
+func (r *RollupsRepo) RebuildCounters(ctx context.Context, classID string) error {
+	for _, stmt := range []string{
+		`DELETE FROM class_rollup_contributions WHERE class_id = $1`,
+		`DELETE FROM class_rollup_pairs WHERE class_id = $1`,
+		`DELETE FROM class_rollup_counters WHERE class_id = $1`,
+		`INSERT INTO class_rollup_contributions (event_db_id, class_id, student_id, assignment_id, event_type, score)
+		 SELECT id, class_id, student_id, COALESCE(assignment_id, ''), type,
+		        CASE WHEN type = 'SUBMISSION_GRADED' THEN normalized_score * 100 END
+		 FROM effective_events
+		 WHERE class_id = $1
+		   AND type IN ('ASSIGNMENT_ASSIGNED', 'SUBMISSION_CREATED', 'SUBMISSION_GRADED', 'ASSIGNMENT_EXCUSED', 'ASSIGNMENT_UNEXCUSED')
+		   AND student_id IS NOT NULL`,
+		`INSERT INTO class_rollup_pairs (class_id, student_id, assignment_id, assigned, graded_count, excused,
+		                                 assigned_at, due_at, submitted_at)
+		 SELECT c.class_id, c.student_id, c.assignment_id, ` + pairAggregates + `
+		 FROM class_rollup_contributions c
+		 JOIN events e ON e.id = c.event_db_id
+		 WHERE c.class_id = $1 AND c.assignment_id <> ''
+		 GROUP BY c.class_id, c.student_id, c.assignment_id`,
+		`UPDATE class_rollup_pairs SET status = ` + pairStatusSQL + ` WHERE class_id = $1`,
+		`INSERT INTO class_rollup_counters (class_id, assigned_pairs, graded_pairs, score_sum, score_count, updated_at)
+		 SELECT $1::varchar,
+		        (SELECT COUNT(*) FROM class_rollup_pairs WHERE class_id = $1 AND assigned AND NOT excused),
+		        (SELECT COUNT(*) FROM class_rollup_pairs WHERE class_id = $1 AND graded_count > 0 AND NOT excused),
+		        COALESCE((SELECT SUM(score) FROM class_rollup_contributions WHERE class_id = $1), 0),
+		        (SELECT COUNT(score) FROM class_rollup_contributions WHERE class_id = $1),
+		        NOW()`,
+	} {
+		if _, err := r.db.Exec(ctx, stmt, classID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
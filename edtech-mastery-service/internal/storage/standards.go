@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/edtech-mastery/student-progress-service/internal/domain"
+)
+
+type StandardsRepo struct {
+	db DBTX
+}
+
+func NewStandardsRepo(pool *pgxpool.Pool) *StandardsRepo {
+	return &StandardsRepo{db: pool}
+}
+
+// WithTx returns a copy of the repo that runs its statements inside tx.
+func (r *StandardsRepo) WithTx(tx pgx.Tx) *StandardsRepo {
+	return &StandardsRepo{db: tx}
+}
+
+func (r *StandardsRepo) ListFrameworks(ctx context.Context) ([]domain.Framework, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, name, description, version, updated_at FROM standard_frameworks ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.Framework{}
+	for rows.Next() {
+		var f domain.Framework
+		if err := rows.Scan(&f.ID, &f.Name, &f.Description, &f.Version, &f.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// GetFramework returns nil if the framework does not exist.
+func (r *StandardsRepo) GetFramework(ctx context.Context, id string) (*domain.Framework, error) {
+	var f domain.Framework
+	err := r.db.QueryRow(ctx,
+		`SELECT id, name, description, version, updated_at FROM standard_frameworks WHERE id = $1`,
+		id,
+	).Scan(&f.ID, &f.Name, &f.Description, &f.Version, &f.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *StandardsRepo) UpsertFramework(ctx context.Context, f domain.Framework) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO standard_frameworks (id, name, description, version, updated_at)
+		 VALUES ($1, $2, $3, $4, NOW())
+		 ON CONFLICT (id) DO UPDATE SET name = $2, description = $3, version = $4, updated_at = NOW()`,
+		f.ID, f.Name, f.Description, f.Version,
+	)
+	return err
+}
+
+// ListStandards returns the framework's items in display order.
+func (r *StandardsRepo) ListStandards(ctx context.Context, frameworkID string) ([]domain.StandardItem, error) {
+	return r.queryStandards(ctx,
+		`SELECT id, framework_id, parent_id, level, code, description, position, updated_at
+		 FROM standards WHERE framework_id = $1 ORDER BY position, id`,
+		frameworkID,
+	)
+}
+
+// GetStandard returns nil if the standard is not in the catalog.
+func (r *StandardsRepo) GetStandard(ctx context.Context, id string) (*domain.StandardItem, error) {
+	items, err := r.queryStandards(ctx,
+		`SELECT id, framework_id, parent_id, level, code, description, position, updated_at
+		 FROM standards WHERE id = $1`,
+		id,
+	)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// StandardsWithAncestors returns the cataloged items among ids together with all their ancestors.
+func (r *StandardsRepo) StandardsWithAncestors(ctx context.Context, ids []string) ([]domain.StandardItem, error) {
+	return r.queryStandards(ctx,
+		`WITH RECURSIVE up AS (
+			SELECT id, framework_id, parent_id, level, code, description, position, updated_at
+			FROM standards WHERE id = ANY($1)
+			UNION
+			SELECT s.id, s.framework_id, s.parent_id, s.level, s.code, s.description, s.position, s.updated_at
+			FROM standards s JOIN up ON s.id = up.parent_id
+		)
+		SELECT id, framework_id, parent_id, level, code, description, position, updated_at
+		FROM up ORDER BY position, id`,
+		ids,
+	)
+}
+
+// FrameworkIDs maps each cataloged standard among ids to its framework.
+func (r *StandardsRepo) FrameworkIDs(ctx context.Context, ids []string) (map[string]string, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, framework_id FROM standards WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]string, len(ids))
+	for rows.Next() {
+		var id, frameworkID string
+		if err := rows.Scan(&id, &frameworkID); err != nil {
+			return nil, err
+		}
+		out[id] = frameworkID
+	}
+	return out, rows.Err()
+}
+
+func (r *StandardsRepo) UpsertStandard(ctx context.Context, s domain.StandardItem) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO standards (id, framework_id, parent_id, level, code, description, position, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		 ON CONFLICT (id) DO UPDATE SET framework_id = $2, parent_id = $3, level = $4, code = $5,
+		   description = $6, position = $7, updated_at = NOW()`,
+		s.ID, s.FrameworkID, s.ParentID, s.Level, s.Code, s.Description, s.Position,
+	)
+	return err
+}
+
+// DeleteStandard reports false if the standard is not in the catalog.
+func (r *StandardsRepo) DeleteStandard(ctx context.Context, id string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM standards WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// HasChildren reports whether any standard names id as its parent.
+func (r *StandardsRepo) HasChildren(ctx context.Context, id string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM standards WHERE parent_id = $1)`, id).Scan(&exists)
+	return exists, err
+}
+
+func (r *StandardsRepo) ListAssociations(ctx context.Context, frameworkID string) ([]domain.StandardAssociation, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, framework_id, association_type, origin_id, destination_id
+		 FROM standard_associations WHERE framework_id = $1 ORDER BY id`,
+		frameworkID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.StandardAssociation{}
+	for rows.Next() {
+		var a domain.StandardAssociation
+		if err := rows.Scan(&a.ID, &a.FrameworkID, &a.AssociationType, &a.OriginID, &a.DestinationID); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// ReplaceAssociations makes assocs the framework's complete association set.
+func (r *StandardsRepo) ReplaceAssociations(ctx context.Context, frameworkID string, assocs []domain.StandardAssociation) error {
+	ids := make([]string, len(assocs))
+	for i, a := range assocs {
+		ids[i] = a.ID
+	}
+	_, err := r.db.Exec(ctx,
+		`DELETE FROM standard_associations WHERE framework_id = $1 AND NOT (id = ANY($2))`,
+		frameworkID, ids,
+	)
+	if err != nil {
+		return err
+	}
+	for _, a := range assocs {
+		_, err := r.db.Exec(ctx,
+			`INSERT INTO standard_associations (id, framework_id, association_type, origin_id, destination_id, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, NOW())
+			 ON CONFLICT (id) DO UPDATE SET framework_id = $2, association_type = $3, origin_id = $4,
+			   destination_id = $5, updated_at = NOW()`,
+			a.ID, frameworkID, a.AssociationType, a.OriginID, a.DestinationID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *StandardsRepo) queryStandards(ctx context.Context, sql string, args ...interface{}) ([]domain.StandardItem, error) {
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.StandardItem{}
+	for rows.Next() {
+		var s domain.StandardItem
+		if err := rows.Scan(&s.ID, &s.FrameworkID, &s.ParentID, &s.Level, &s.Code, &s.Description, &s.Position, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
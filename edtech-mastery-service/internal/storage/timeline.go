@@ -21,10 +21,15 @@ func (r *TimelineRepo) GetRecentEventsForStudentClass(ctx context.Context, stude
 		limit = 50
 	}
 	rows, err := r.pool.Query(ctx,
-		`SELECT e.type, (e.payload->>'assignment_id')::text, (e.payload->>'score')::float, e.created_at
+		`SELECT e.event_id, e.type, e.assignment_id, (e.payload->>'score')::float, COALESCE(e.payload->>'grade', ''), e.normalized_score,
+		        COALESCE(e.payload->>'original_event_id', ''),
+		        v.id IS NULL AND e.type NOT IN ('GRADE_UPDATED', 'EVENT_RETRACTED'),
+		        CASE WHEN v.corrected_by IS NOT NULL THEN v.normalized_score END,
+		        e.occurred_at, e.created_at
 		 FROM events e
-		 WHERE e.payload->>'student_id' = $1 AND e.payload->>'class_id' = $2
-		 ORDER BY e.created_at DESC LIMIT $3`,
+		 LEFT JOIN effective_events v ON v.id = e.id
+		 WHERE e.student_id = $1 AND e.class_id = $2
+		 ORDER BY e.occurred_at DESC, e.id DESC LIMIT $3`,
 		studentID, classID, limit,
 	)
 	if err != nil {
@@ -37,7 +42,8 @@ func (r *TimelineRepo) GetRecentEventsForStudentClass(ctx context.Context, stude
 		var t domain.TimelineEvent
 		var score *float64
 		var assignmentID *string
-		if err := rows.Scan(&t.EventType, &assignmentID, &score, &t.CreatedAt); err != nil {
+		if err := rows.Scan(&t.EventID, &t.EventType, &assignmentID, &score, &t.Grade, &t.NormalizedScore,
+			&t.Corrects, &t.Retracted, &t.CorrectedScore, &t.OccurredAt, &t.CreatedAt); err != nil {
 			return nil, err
 		}
 		if assignmentID != nil {
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UnitOfWork runs a group of repository calls in a single transaction. Repos join it through their WithTx method.
+type UnitOfWork struct {
+	pool *pgxpool.Pool
+}
+
+func NewUnitOfWork(pool *pgxpool.Pool) *UnitOfWork {
+	return &UnitOfWork{pool: pool}
+}
+
+// Do runs fn in a transaction that is committed if fn returns nil and rolled back otherwise.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Savepoint runs fn in a savepoint of tx, so a failure undoes only fn's statements and leaves tx usable.
+func Savepoint(ctx context.Context, tx pgx.Tx, fn func(tx pgx.Tx) error) error {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer sp.Rollback(ctx)
+	if err := fn(sp); err != nil {
+		return err
+	}
+	return sp.Commit(ctx)
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_outbox_dead;
+UPDATE event_outbox SET status = 'pending' WHERE status = 'dead';
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS dead_at;
@@ -0,0 +1,4 @@
+-- dead-lettered outbox rows: status = 'dead' once attempts are exhausted
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS dead_at TIMESTAMPTZ;
+
+CREATE INDEX idx_outbox_dead ON event_outbox(id) WHERE status = 'dead';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_outbox_pending_due;
+CREATE INDEX IF NOT EXISTS idx_outbox_status ON event_outbox(status) WHERE status = 'pending';
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS next_attempt_at;
@@ -0,0 +1,5 @@
+-- scheduled retry: a pending row is only claimable once next_attempt_at has passed
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+DROP INDEX IF EXISTS idx_outbox_status;
+CREATE INDEX idx_outbox_pending_due ON event_outbox(next_attempt_at, id) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_outbox_lease;
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS locked_by;
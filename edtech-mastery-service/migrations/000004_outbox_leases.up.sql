@@ -0,0 +1,8 @@
+-- claim leases: a 'processing' row whose lease expired is returned to 'pending' by the reaper
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS locked_by VARCHAR(255);
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+
+-- rows already stuck before leases existed become reapable right away
+UPDATE event_outbox SET locked_until = NOW() WHERE status = 'processing';
+
+CREATE INDEX idx_outbox_lease ON event_outbox(locked_until) WHERE status = 'processing';
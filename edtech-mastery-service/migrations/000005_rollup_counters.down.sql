@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS class_rollup_counters;
+DROP TABLE IF EXISTS class_rollup_pairs;
+DROP TABLE IF EXISTS class_rollup_contributions;
@@ -0,0 +1,64 @@
+-- class_rollup_contributions: one row per event folded into the counters (re-applying an event is a no-op)
+CREATE TABLE IF NOT EXISTS class_rollup_contributions (
+    event_db_id BIGINT PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    class_id VARCHAR(255) NOT NULL,
+    student_id VARCHAR(255) NOT NULL,
+    assignment_id VARCHAR(255) NOT NULL DEFAULT '',
+    event_type VARCHAR(64) NOT NULL,
+    score DOUBLE PRECISION,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_rollup_contributions_class ON class_rollup_contributions(class_id);
+
+-- class_rollup_pairs: per (student, assignment) state behind the distinct-pair counters
+CREATE TABLE IF NOT EXISTS class_rollup_pairs (
+    class_id VARCHAR(255) NOT NULL,
+    student_id VARCHAR(255) NOT NULL,
+    assignment_id VARCHAR(255) NOT NULL,
+    assigned BOOLEAN NOT NULL DEFAULT FALSE,
+    graded_count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (class_id, student_id, assignment_id)
+);
+
+-- class_rollup_counters: incrementally maintained inputs of class_rollups
+CREATE TABLE IF NOT EXISTS class_rollup_counters (
+    class_id VARCHAR(255) PRIMARY KEY,
+    assigned_pairs BIGINT NOT NULL DEFAULT 0,
+    graded_pairs BIGINT NOT NULL DEFAULT 0,
+    score_sum DOUBLE PRECISION NOT NULL DEFAULT 0,
+    score_count BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- backfill from existing events
+INSERT INTO class_rollup_contributions (event_db_id, class_id, student_id, assignment_id, event_type, score)
+SELECT id, payload->>'class_id', payload->>'student_id', COALESCE(payload->>'assignment_id', ''), type,
+       CASE WHEN type = 'SUBMISSION_GRADED' THEN (payload->>'score')::float END
+FROM events
+WHERE type IN ('ASSIGNMENT_ASSIGNED', 'SUBMISSION_GRADED')
+  AND payload->>'class_id' IS NOT NULL AND payload->>'student_id' IS NOT NULL
+ON CONFLICT (event_db_id) DO NOTHING;
+
+INSERT INTO class_rollup_pairs (class_id, student_id, assignment_id, assigned, graded_count)
+SELECT class_id, student_id, assignment_id,
+       BOOL_OR(event_type = 'ASSIGNMENT_ASSIGNED'),
+       COUNT(*) FILTER (WHERE event_type = 'SUBMISSION_GRADED')
+FROM class_rollup_contributions
+WHERE assignment_id <> ''
+GROUP BY class_id, student_id, assignment_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO class_rollup_counters (class_id, assigned_pairs, graded_pairs, score_sum, score_count)
+SELECT c.class_id,
+       COALESCE(p.assigned_pairs, 0), COALESCE(p.graded_pairs, 0),
+       COALESCE(SUM(c.score), 0), COUNT(c.score)
+FROM class_rollup_contributions c
+LEFT JOIN (
+    SELECT class_id,
+           COUNT(*) FILTER (WHERE assigned) AS assigned_pairs,
+           COUNT(*) FILTER (WHERE graded_count > 0) AS graded_pairs
+    FROM class_rollup_pairs GROUP BY class_id
+) p ON p.class_id = c.class_id
+GROUP BY c.class_id, p.assigned_pairs, p.graded_pairs
+ON CONFLICT (class_id) DO NOTHING;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_events_student_class_created;
+DROP INDEX IF EXISTS idx_events_class_created;
+DROP INDEX IF EXISTS idx_events_class_type;
+ALTER TABLE events DROP COLUMN IF EXISTS assignment_id;
+ALTER TABLE events DROP COLUMN IF EXISTS class_id;
+ALTER TABLE events DROP COLUMN IF EXISTS student_id;
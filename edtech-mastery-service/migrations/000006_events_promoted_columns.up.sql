@@ -0,0 +1,14 @@
+-- typed copies of the JSONB identifiers every read path filters on
+ALTER TABLE events ADD COLUMN IF NOT EXISTS student_id VARCHAR(255);
+ALTER TABLE events ADD COLUMN IF NOT EXISTS class_id VARCHAR(255);
+ALTER TABLE events ADD COLUMN IF NOT EXISTS assignment_id VARCHAR(255);
+
+UPDATE events
+SET student_id = payload->>'student_id',
+    class_id = payload->>'class_id',
+    assignment_id = NULLIF(payload->>'assignment_id', '')
+WHERE class_id IS NULL;
+
+CREATE INDEX idx_events_class_type ON events(class_id, type);
+CREATE INDEX idx_events_class_created ON events(class_id, created_at DESC);
+CREATE INDEX idx_events_student_class_created ON events(student_id, class_id, created_at DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_events_student_class_occurred;
+DROP INDEX IF EXISTS idx_events_class_occurred;
+CREATE INDEX IF NOT EXISTS idx_events_class_created ON events(class_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_events_student_class_created ON events(student_id, class_id, created_at DESC);
+ALTER TABLE events DROP COLUMN IF EXISTS occurred_at;
@@ -0,0 +1,17 @@
+-- occurred_at: when the event happened according to its source; all ordering uses it instead of created_at
+ALTER TABLE events ADD COLUMN IF NOT EXISTS occurred_at TIMESTAMPTZ;
+
+UPDATE events
+SET occurred_at = CASE
+    WHEN payload->>'timestamp' IS NULL OR payload->>'timestamp' LIKE '0001-01-01%' THEN created_at
+    ELSE (payload->>'timestamp')::timestamptz
+END
+WHERE occurred_at IS NULL;
+
+ALTER TABLE events ALTER COLUMN occurred_at SET DEFAULT NOW();
+ALTER TABLE events ALTER COLUMN occurred_at SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_events_class_created;
+DROP INDEX IF EXISTS idx_events_student_class_created;
+CREATE INDEX idx_events_class_occurred ON events(class_id, occurred_at DESC, id DESC);
+CREATE INDEX idx_events_student_class_occurred ON events(student_id, class_id, occurred_at DESC, id DESC);
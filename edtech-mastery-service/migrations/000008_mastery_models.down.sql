@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS mastery_model_settings;
+
+ALTER TABLE student_mastery
+    DROP COLUMN IF EXISTS last_evidence_at,
+    DROP COLUMN IF EXISTS evidence_count,
+    DROP COLUMN IF EXISTS evidence_weight,
+    DROP COLUMN IF EXISTS model;
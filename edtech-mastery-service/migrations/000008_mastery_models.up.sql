@@ -0,0 +1,21 @@
+-- student_mastery: model state carried between observations
+ALTER TABLE student_mastery
+    ADD COLUMN IF NOT EXISTS model VARCHAR(64) NOT NULL DEFAULT 'last_score',
+    ADD COLUMN IF NOT EXISTS evidence_weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS evidence_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_evidence_at TIMESTAMPTZ;
+
+-- existing rows hold one last-score observation
+UPDATE student_mastery
+SET evidence_weight = 1, evidence_count = 1, last_evidence_at = updated_at
+WHERE evidence_count = 0;
+
+-- mastery_model_settings: which model a class or standard framework uses (class wins)
+CREATE TABLE IF NOT EXISTS mastery_model_settings (
+    scope VARCHAR(16) NOT NULL CHECK (scope IN ('class', 'framework')),
+    scope_id VARCHAR(255) NOT NULL,
+    model VARCHAR(64) NOT NULL,
+    params JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, scope_id)
+);
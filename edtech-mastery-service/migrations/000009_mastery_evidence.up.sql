@@ -0,0 +1,21 @@
+-- mastery_evidence: append-only record of each graded event's contribution to a student's standard mastery
+CREATE TABLE IF NOT EXISTS mastery_evidence (
+    id BIGSERIAL PRIMARY KEY,
+    student_id VARCHAR(255) NOT NULL,
+    standard_id VARCHAR(255) NOT NULL,
+    event_db_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    class_id VARCHAR(255) NOT NULL,
+    assignment_id VARCHAR(255),
+    model VARCHAR(64) NOT NULL,
+    raw_score DOUBLE PRECISION NOT NULL,
+    evidence_score DOUBLE PRECISION NOT NULL,
+    weight DOUBLE PRECISION NOT NULL,
+    mastery_before DOUBLE PRECISION,
+    mastery_after DOUBLE PRECISION NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- an event contributes to a standard once, however often it is processed
+    UNIQUE (event_db_id, standard_id)
+);
+
+CREATE INDEX idx_mastery_evidence_student_standard ON mastery_evidence(student_id, standard_id, id);
@@ -0,0 +1,9 @@
+ALTER TABLE mastery_evidence
+    DROP COLUMN IF EXISTS points_possible,
+    DROP COLUMN IF EXISTS scale,
+    DROP COLUMN IF EXISTS grade;
+
+DELETE FROM mastery_evidence WHERE raw_score IS NULL;
+ALTER TABLE mastery_evidence ALTER COLUMN raw_score SET NOT NULL;
+
+ALTER TABLE events DROP COLUMN IF EXISTS normalized_score;
@@ -0,0 +1,14 @@
+-- events.normalized_score: the graded score on 0..1, whatever scale it was reported on
+ALTER TABLE events ADD COLUMN IF NOT EXISTS normalized_score DOUBLE PRECISION;
+
+-- events before score scales were percentages
+UPDATE events
+SET normalized_score = LEAST(GREATEST((payload->>'score')::float, 0), 100) / 100
+WHERE normalized_score IS NULL AND payload->>'score' IS NOT NULL;
+
+-- mastery_evidence: letter and pass/fail grades have no numeric raw score
+ALTER TABLE mastery_evidence
+    ALTER COLUMN raw_score DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS grade VARCHAR(16),
+    ADD COLUMN IF NOT EXISTS scale VARCHAR(32),
+    ADD COLUMN IF NOT EXISTS points_possible DOUBLE PRECISION;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS mastery_band_transitions;
+ALTER TABLE student_mastery DROP COLUMN IF EXISTS band;
+DROP TABLE IF EXISTS mastery_band_settings;
+DROP TABLE IF EXISTS class_districts;
@@ -0,0 +1,50 @@
+-- class_districts: which district a class belongs to (district-scoped settings)
+CREATE TABLE IF NOT EXISTS class_districts (
+    class_id VARCHAR(255) PRIMARY KEY,
+    district_id VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- mastery_band_settings: lowest mastery score of each band above beginning, per district or standard framework (district wins)
+CREATE TABLE IF NOT EXISTS mastery_band_settings (
+    scope VARCHAR(16) NOT NULL CHECK (scope IN ('district', 'framework')),
+    scope_id VARCHAR(255) NOT NULL,
+    developing DOUBLE PRECISION NOT NULL,
+    proficient DOUBLE PRECISION NOT NULL,
+    advanced DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, scope_id),
+    CHECK (0 < developing AND developing < proficient AND proficient < advanced AND advanced <= 1)
+);
+
+-- student_mastery.band: banded with the default cut-points until the next evidence
+ALTER TABLE student_mastery ADD COLUMN IF NOT EXISTS band VARCHAR(16) NOT NULL DEFAULT 'beginning';
+
+UPDATE student_mastery
+SET band = CASE
+    WHEN mastery_score >= 0.9 THEN 'advanced'
+    WHEN mastery_score >= 0.7 THEN 'proficient'
+    WHEN mastery_score >= 0.4 THEN 'developing'
+    ELSE 'beginning'
+END;
+
+-- mastery_band_transitions: append-only record of band changes
+CREATE TABLE IF NOT EXISTS mastery_band_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    student_id VARCHAR(255) NOT NULL,
+    standard_id VARCHAR(255) NOT NULL,
+    class_id VARCHAR(255) NOT NULL,
+    event_db_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    from_band VARCHAR(16) NOT NULL,
+    to_band VARCHAR(16) NOT NULL,
+    from_rank SMALLINT NOT NULL,
+    to_rank SMALLINT NOT NULL,
+    mastery_before DOUBLE PRECISION NOT NULL,
+    mastery_after DOUBLE PRECISION NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (event_db_id, standard_id)
+);
+
+CREATE INDEX idx_band_transitions_class ON mastery_band_transitions(class_id, occurred_at DESC);
+CREATE INDEX idx_band_transitions_student ON mastery_band_transitions(student_id, standard_id);
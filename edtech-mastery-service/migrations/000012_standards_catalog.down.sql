@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS standards;
+DROP TABLE IF EXISTS standard_frameworks;
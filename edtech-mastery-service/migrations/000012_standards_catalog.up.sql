@@ -0,0 +1,22 @@
+-- standard_frameworks: standards catalogs (e.g. CCSS Math)
+CREATE TABLE IF NOT EXISTS standard_frameworks (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- standards: catalog items; id is the standard ID used in events. Hierarchy: subject > domain > cluster > standard
+CREATE TABLE IF NOT EXISTS standards (
+    id VARCHAR(255) PRIMARY KEY,
+    framework_id VARCHAR(255) NOT NULL REFERENCES standard_frameworks(id) ON DELETE CASCADE,
+    parent_id VARCHAR(255) REFERENCES standards(id),
+    level VARCHAR(16) NOT NULL CHECK (level IN ('subject', 'domain', 'cluster', 'standard')),
+    code VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    position INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_standards_framework ON standards(framework_id, position);
+CREATE INDEX idx_standards_parent ON standards(parent_id);
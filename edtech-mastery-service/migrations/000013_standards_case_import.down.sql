@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS standard_associations;
+ALTER TABLE standard_frameworks DROP COLUMN IF EXISTS version;
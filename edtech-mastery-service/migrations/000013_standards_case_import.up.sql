@@ -0,0 +1,16 @@
+-- standard_frameworks.version: the CASE document version last imported
+ALTER TABLE standard_frameworks ADD COLUMN IF NOT EXISTS version VARCHAR(64) NOT NULL DEFAULT '';
+
+-- standard_associations: CASE associations other than the isChildOf hierarchy (e.g. exactMatchOf crosswalks).
+-- destination_id may point outside the catalog, so neither end is a foreign key.
+CREATE TABLE IF NOT EXISTS standard_associations (
+    id VARCHAR(255) PRIMARY KEY,
+    framework_id VARCHAR(255) NOT NULL REFERENCES standard_frameworks(id) ON DELETE CASCADE,
+    association_type VARCHAR(64) NOT NULL,
+    origin_id VARCHAR(255) NOT NULL,
+    destination_id VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_standard_associations_framework ON standard_associations(framework_id);
+CREATE INDEX idx_standard_associations_origin ON standard_associations(origin_id);
@@ -0,0 +1 @@
+ALTER TABLE mastery_evidence DROP COLUMN IF EXISTS criteria;
@@ -0,0 +1,2 @@
+-- mastery_evidence.criteria: rubric criteria the evidence score was taken from (NULL when it is the event's overall score)
+ALTER TABLE mastery_evidence ADD COLUMN IF NOT EXISTS criteria TEXT[];
@@ -0,0 +1,9 @@
+-- assignment_standard_weights: how much of an assignment assesses each of its standards (relative weights).
+-- Used for graded events of the assignment that carry no standard_weights of their own.
+CREATE TABLE IF NOT EXISTS assignment_standard_weights (
+    assignment_id VARCHAR(255) NOT NULL,
+    standard_id VARCHAR(255) NOT NULL,
+    weight DOUBLE PRECISION NOT NULL CHECK (weight >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (assignment_id, standard_id)
+);
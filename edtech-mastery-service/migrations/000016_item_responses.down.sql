@@ -0,0 +1,2 @@
+ALTER TABLE mastery_evidence DROP COLUMN IF EXISTS item_id;
+DROP TABLE IF EXISTS item_responses;
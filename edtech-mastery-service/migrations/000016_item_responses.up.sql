@@ -0,0 +1,17 @@
+-- item_responses: scored answers to quiz items (ITEM_RESPONDED events), the input of item analysis
+CREATE TABLE IF NOT EXISTS item_responses (
+    event_db_id BIGINT PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    student_id VARCHAR(255) NOT NULL,
+    class_id VARCHAR(255) NOT NULL,
+    assignment_id VARCHAR(255) NOT NULL,
+    item_id VARCHAR(255) NOT NULL,
+    standard_ids TEXT[] NOT NULL DEFAULT '{}',
+    credit DOUBLE PRECISION NOT NULL CHECK (credit >= 0 AND credit <= 1),
+    response_time_ms BIGINT,
+    occurred_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_item_responses_assignment ON item_responses(assignment_id, student_id, item_id, occurred_at DESC);
+
+-- mastery_evidence.item_id: the quiz item behind item-level evidence
+ALTER TABLE mastery_evidence ADD COLUMN IF NOT EXISTS item_id VARCHAR(255);
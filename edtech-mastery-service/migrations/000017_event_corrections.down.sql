@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS effective_events;
+ALTER TABLE mastery_evidence DROP COLUMN IF EXISTS voided_by;
+DROP TABLE IF EXISTS event_corrections;
@@ -0,0 +1,34 @@
+-- event_corrections: GRADE_UPDATED and EVENT_RETRACTED events applied to the event they reference.
+-- Events stay append-only; this ledger says which ones were corrected, and makes reapplying a correction a no-op.
+CREATE TABLE IF NOT EXISTS event_corrections (
+    event_db_id BIGINT PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    original_event_db_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    normalized_score DOUBLE PRECISION,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_event_corrections_original ON event_corrections(original_event_db_id, kind, occurred_at DESC);
+
+-- mastery_evidence.voided_by: the correction that withdrew this evidence; voided rows are kept for the audit trail
+ALTER TABLE mastery_evidence ADD COLUMN IF NOT EXISTS voided_by BIGINT REFERENCES events(id);
+
+-- effective_events: events as corrected. Retracted events and the corrections themselves are left out, and
+-- normalized_score is the latest GRADE_UPDATED score (by its timestamp) if there is one.
+CREATE OR REPLACE VIEW effective_events AS
+SELECT e.id, e.event_id, e.source, e.type, e.student_id, e.class_id, e.assignment_id, e.occurred_at, e.created_at,
+       COALESCE(u.normalized_score, e.normalized_score) AS normalized_score,
+       u.event_db_id AS corrected_by
+FROM events e
+LEFT JOIN LATERAL (
+    SELECT c.event_db_id, c.normalized_score
+    FROM event_corrections c
+    WHERE c.original_event_db_id = e.id AND c.kind = 'GRADE_UPDATED'
+    ORDER BY c.occurred_at DESC, c.event_db_id DESC
+    LIMIT 1
+) u ON TRUE
+WHERE e.type NOT IN ('GRADE_UPDATED', 'EVENT_RETRACTED')
+  AND NOT EXISTS (
+    SELECT 1 FROM event_corrections r WHERE r.original_event_db_id = e.id AND r.kind = 'EVENT_RETRACTED'
+  );
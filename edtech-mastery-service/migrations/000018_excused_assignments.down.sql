@@ -0,0 +1,2 @@
+DROP VIEW IF EXISTS excused_pairs;
+ALTER TABLE class_rollup_pairs DROP COLUMN IF EXISTS excused;
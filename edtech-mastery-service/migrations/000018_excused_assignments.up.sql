@@ -0,0 +1,15 @@
+-- class_rollup_pairs.excused: the pair's latest ASSIGNMENT_EXCUSED / ASSIGNMENT_UNEXCUSED event excuses it;
+-- excused pairs count in neither assigned_pairs nor graded_pairs
+ALTER TABLE class_rollup_pairs ADD COLUMN IF NOT EXISTS excused BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- excused_pairs: (student, assignment) pairs currently excused, by the latest excuse or un-excuse event
+-- (by its timestamp). Retracted events are ignored like everywhere else.
+CREATE OR REPLACE VIEW excused_pairs AS
+SELECT class_id, student_id, assignment_id
+FROM (
+    SELECT DISTINCT ON (class_id, student_id, assignment_id) class_id, student_id, assignment_id, type
+    FROM effective_events
+    WHERE type IN ('ASSIGNMENT_EXCUSED', 'ASSIGNMENT_UNEXCUSED')
+    ORDER BY class_id, student_id, assignment_id, occurred_at DESC, id DESC
+) latest
+WHERE type = 'ASSIGNMENT_EXCUSED';
@@ -0,0 +1,7 @@
+DELETE FROM class_rollup_contributions WHERE event_type = 'SUBMISSION_CREATED';
+DROP INDEX IF EXISTS idx_rollup_pairs_due;
+ALTER TABLE class_rollup_pairs
+    DROP COLUMN IF EXISTS assigned_at,
+    DROP COLUMN IF EXISTS due_at,
+    DROP COLUMN IF EXISTS submitted_at,
+    DROP COLUMN IF EXISTS status;
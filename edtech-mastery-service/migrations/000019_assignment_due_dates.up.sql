@@ -0,0 +1,35 @@
+-- class_rollup_pairs: due date and first submission of each (student, assignment), and the status derived from them
+-- (not_yet_due, on_time, late, missing). The worker moves overdue pairs to missing on a schedule.
+ALTER TABLE class_rollup_pairs
+    ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'not_yet_due';
+
+CREATE INDEX idx_rollup_pairs_due ON class_rollup_pairs(due_at) WHERE status = 'not_yet_due';
+
+-- SUBMISSION_CREATED now contributes too (it dates the submission); backfill it from existing events
+INSERT INTO class_rollup_contributions (event_db_id, class_id, student_id, assignment_id, event_type, score)
+SELECT id, class_id, student_id, COALESCE(assignment_id, ''), type, NULL
+FROM effective_events
+WHERE type = 'SUBMISSION_CREATED' AND class_id IS NOT NULL AND student_id IS NOT NULL
+ON CONFLICT (event_db_id) DO NOTHING;
+
+INSERT INTO class_rollup_pairs (class_id, student_id, assignment_id, assigned_at, due_at, submitted_at)
+SELECT c.class_id, c.student_id, c.assignment_id,
+       MAX(e.occurred_at) FILTER (WHERE c.event_type = 'ASSIGNMENT_ASSIGNED'),
+       (ARRAY_AGG((e.payload->>'due_at')::timestamptz ORDER BY e.occurred_at DESC, e.id DESC)
+            FILTER (WHERE c.event_type = 'ASSIGNMENT_ASSIGNED'))[1],
+       MIN(e.occurred_at) FILTER (WHERE c.event_type IN ('SUBMISSION_CREATED', 'SUBMISSION_GRADED'))
+FROM class_rollup_contributions c
+JOIN events e ON e.id = c.event_db_id
+WHERE c.assignment_id <> ''
+GROUP BY c.class_id, c.student_id, c.assignment_id
+ON CONFLICT (class_id, student_id, assignment_id) DO UPDATE SET
+    assigned_at = EXCLUDED.assigned_at, due_at = EXCLUDED.due_at, submitted_at = EXCLUDED.submitted_at;
+
+UPDATE class_rollup_pairs SET status = CASE
+    WHEN submitted_at IS NOT NULL THEN CASE WHEN submitted_at > due_at THEN 'late' ELSE 'on_time' END
+    WHEN due_at <= NOW() THEN 'missing'
+    ELSE 'not_yet_due'
+END;
@@ -0,0 +1,10 @@
+-- risk_settings: at-risk rule thresholds per class or district (class wins, then the class's district, then defaults)
+CREATE TABLE IF NOT EXISTS risk_settings (
+    scope VARCHAR(16) NOT NULL CHECK (scope IN ('class', 'district')),
+    scope_id VARCHAR(255) NOT NULL,
+    missing_count INT NOT NULL CHECK (missing_count >= 1),
+    trend_window INT NOT NULL CHECK (trend_window BETWEEN 2 AND 20),
+    median_margin DOUBLE PRECISION NOT NULL CHECK (median_margin >= 0 AND median_margin < 1),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, scope_id)
+);
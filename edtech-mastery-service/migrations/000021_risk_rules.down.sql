@@ -0,0 +1,2 @@
+ALTER TABLE risk_flags DROP COLUMN IF EXISTS evidence;
+ALTER TABLE risk_settings DROP COLUMN IF EXISTS enabled_rules;
@@ -0,0 +1,5 @@
+-- risk_settings.enabled_rules: the rules run for the class or district; NULL runs every registered rule
+ALTER TABLE risk_settings ADD COLUMN IF NOT EXISTS enabled_rules TEXT[];
+
+-- risk_flags.evidence: why the rule flagged the student (rule-specific JSON, e.g. the missing count and threshold)
+ALTER TABLE risk_flags ADD COLUMN IF NOT EXISTS evidence JSONB;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS risk_flag_history;
+DROP INDEX IF EXISTS idx_risk_flags_student;
+DELETE FROM risk_flags WHERE NOT active;
+ALTER TABLE risk_flags
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS active,
+    DROP COLUMN IF EXISTS first_flagged_at,
+    DROP COLUMN IF EXISTS acknowledged_at,
+    DROP COLUMN IF EXISTS acknowledged_by,
+    DROP COLUMN IF EXISTS note,
+    DROP COLUMN IF EXISTS resolved_at;
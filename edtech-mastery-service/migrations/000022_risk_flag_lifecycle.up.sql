@@ -0,0 +1,35 @@
+-- risk_flags lifecycle: a flag is open until a teacher acknowledges or resolves it, or its rule stops firing
+-- (auto_cleared). active is whether the rule flagged the student on the class's last recompute; an inactive flag
+-- that fires again is reopened with a new first_flagged_at.
+ALTER TABLE risk_flags
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'acknowledged', 'resolved', 'auto_cleared')),
+    ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS first_flagged_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS acknowledged_by VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS note TEXT,
+    ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMPTZ;
+
+UPDATE risk_flags SET first_flagged_at = computed_at WHERE first_flagged_at IS NULL;
+ALTER TABLE risk_flags
+    ALTER COLUMN first_flagged_at SET NOT NULL,
+    ALTER COLUMN first_flagged_at SET DEFAULT NOW();
+
+CREATE INDEX idx_risk_flags_student ON risk_flags(class_id, student_id);
+
+-- risk_flag_history: every status change of a flag, by the worker (actor NULL) or a teacher
+CREATE TABLE IF NOT EXISTS risk_flag_history (
+    id BIGSERIAL PRIMARY KEY,
+    flag_id BIGINT NOT NULL REFERENCES risk_flags(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    actor VARCHAR(255),
+    note TEXT,
+    evidence JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_risk_flag_history_flag ON risk_flag_history(flag_id, created_at);
+
+INSERT INTO risk_flag_history (flag_id, status, evidence, created_at)
+SELECT id, 'open', evidence, first_flagged_at FROM risk_flags;
@@ -31,6 +31,57 @@ var (
 		[]string{"type"},
 	)
 
+	OutboxDeadLettered = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "edtech_outbox_dead_lettered_total",
+			Help: "Total outbox items moved to the dead-letter state after exhausting their attempts",
+		},
+	)
+
+	OutboxDeadLetterActions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "edtech_outbox_dead_letter_actions_total",
+			Help: "Admin actions taken on dead-lettered outbox items",
+		},
+		[]string{"action"},
+	)
+
+	OutboxStuckItems = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "edtech_outbox_stuck_items",
+			Help: "Outbox items found in processing with an expired lease on the last reaper pass",
+		},
+	)
+
+	OutboxLeasesReclaimed = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "edtech_outbox_leases_reclaimed_total",
+			Help: "Total outbox items whose expired lease was reclaimed by the reaper",
+		},
+	)
+
+	ClassRecomputes = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "edtech_worker_class_recomputes_total",
+			Help: "Per-class rollup and risk recomputes: performed, or coalesced into another event's recompute in the same batch",
+		},
+		[]string{"outcome"},
+	)
+
+	AssignmentsMarkedMissing = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "edtech_worker_assignments_marked_missing_total",
+			Help: "Total (student, assignment) pairs the overdue sweep marked missing after their due date passed",
+		},
+	)
+
+	WorkerListenerConnected = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "edtech_worker_listener_connected",
+			Help: "1 while the worker's LISTEN connection for outbox wakeups is up, 0 while it falls back to polling",
+		},
+	)
+
 	DashboardQueryLatency = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "edtech_dashboard_query_seconds",